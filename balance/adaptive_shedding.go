@@ -0,0 +1,122 @@
+package balance
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const sheddingBuckets = 10
+
+// SheddingConfig 自适应限流的配置
+type SheddingConfig struct {
+	Window      time.Duration // 错误率的滑动窗口
+	Threshold   float64       // 错误率阈值，超过后开始丢弃请求
+	MinRequests int           // 窗口内请求数少于该值时不做判断
+}
+
+type sheddingBucket struct {
+	slot     int64
+	total    int
+	failures int
+}
+
+// AdaptiveSheddingBalancer
+// 全局熔断：统计窗口内所有服务器的总错误率，超过阈值后按比例丢弃请求（返回 ErrShedding），
+// 给后端喘息的时间；错误率下降后丢弃比例随之降低
+// 错误率为 r、阈值为 t 时，丢弃比例为 (r-t)/(1-t)
+type AdaptiveSheddingBalancer struct {
+	balancer Balancer
+	cfg      SheddingConfig
+	clock    Clock
+	buckets  [sheddingBuckets]sheddingBucket
+	rng      *rand.Rand
+	lock     sync.Mutex
+}
+
+// NewAdaptiveSheddingBalancer 支持 WithClock 和 WithRandSource
+func NewAdaptiveSheddingBalancer(b Balancer, cfg SheddingConfig, opts ...Option) *AdaptiveSheddingBalancer {
+	o := newOptions(opts)
+	if cfg.Window < sheddingBuckets {
+		cfg.Window = sheddingBuckets
+	}
+	return &AdaptiveSheddingBalancer{
+		balancer: b,
+		cfg:      cfg,
+		clock:    o.clock,
+		rng:      rand.New(o.source),
+	}
+}
+
+// Report 上报一次请求结果
+func (a *AdaptiveSheddingBalancer) Report(addr string, err error) {
+	slot := a.slot()
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	b := &a.buckets[slot%sheddingBuckets]
+	if b.slot != slot {
+		*b = sheddingBucket{slot: slot}
+	}
+	b.total++
+	if err != nil {
+		b.failures++
+	}
+}
+
+// ShedFraction 当前的丢弃比例（0~1）
+func (a *AdaptiveSheddingBalancer) ShedFraction() float64 {
+	slot := a.slot()
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.shedFractionLocked(slot)
+}
+
+func (a *AdaptiveSheddingBalancer) Next() string {
+	addr, _ := a.NextE()
+	return addr
+}
+
+func (a *AdaptiveSheddingBalancer) NextE() (string, error) {
+	slot := a.slot()
+	a.lock.Lock()
+	shed := a.rng.Float64() < a.shedFractionLocked(slot)
+	a.lock.Unlock()
+	if shed {
+		return "", ErrShedding
+	}
+
+	if eb, ok := a.balancer.(ErrorBalancer); ok {
+		return eb.NextE()
+	}
+	addr := a.balancer.Next()
+	if addr == "" {
+		return "", ErrAllUnavailable
+	}
+	return addr, nil
+}
+
+func (a *AdaptiveSheddingBalancer) slot() int64 {
+	return a.clock.Now().UnixNano() / int64(a.cfg.Window/sheddingBuckets)
+}
+
+func (a *AdaptiveSheddingBalancer) shedFractionLocked(slot int64) float64 {
+	total, failures := 0, 0
+	for _, b := range a.buckets {
+		if b.slot > slot-sheddingBuckets && b.slot <= slot {
+			total += b.total
+			failures += b.failures
+		}
+	}
+	if total == 0 || total < a.cfg.MinRequests {
+		return 0
+	}
+	rate := float64(failures) / float64(total)
+	if rate <= a.cfg.Threshold {
+		return 0
+	}
+	if a.cfg.Threshold >= 1 {
+		return 0
+	}
+	return (rate - a.cfg.Threshold) / (1 - a.cfg.Threshold)
+}
@@ -0,0 +1,106 @@
+package balance
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func shedRate(b *AdaptiveSheddingBalancer, iterations int) float64 {
+	shed := 0
+	for i := 0; i < iterations; i++ {
+		if _, err := b.NextE(); errors.Is(err, ErrShedding) {
+			shed++
+		}
+	}
+	return float64(shed) / float64(iterations)
+}
+
+func TestAdaptiveSheddingBalancer_ShedsAndRecovers(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewAdaptiveSheddingBalancer(NewRoundRobinBalancer([]string{"s1", "s2"}), SheddingConfig{
+		Window:      10 * time.Second,
+		Threshold:   0.2,
+		MinRequests: 10,
+	}, WithClock(clock))
+
+	if rate := shedRate(balancer, 1000); rate != 0 {
+		t.Errorf("shed rate = %.3f without errors, want 0", rate)
+	}
+
+	// 持续 60% 错误率：丢弃比例 (0.6-0.2)/(1-0.2) = 0.5
+	boom := errors.New("boom")
+	for i := 0; i < 100; i++ {
+		var err error
+		if i%5 < 3 {
+			err = boom
+		}
+		balancer.Report("s1", err)
+	}
+	if f := balancer.ShedFraction(); math.Abs(f-0.5) > 1e-9 {
+		t.Errorf("ShedFraction() = %.3f, want 0.5", f)
+	}
+	if rate := shedRate(balancer, 10000); rate < 0.47 || rate > 0.53 {
+		t.Errorf("shed rate = %.3f, want ~0.5", rate)
+	}
+
+	// 错误率下降后丢弃比例随之降低
+	clock.Advance(5 * time.Second)
+	for i := 0; i < 300; i++ {
+		balancer.Report("s1", nil)
+	}
+	eased := balancer.ShedFraction()
+	if eased >= 0.5 {
+		t.Errorf("ShedFraction() = %.3f, want eased below 0.5", eased)
+	}
+
+	// 高错误率的数据滑出窗口后完全恢复
+	clock.Advance(6 * time.Second)
+	if f := balancer.ShedFraction(); f != 0 {
+		t.Errorf("ShedFraction() = %.3f after window, want 0", f)
+	}
+}
+
+func TestAdaptiveSheddingBalancer_MinRequests(t *testing.T) {
+	balancer := NewAdaptiveSheddingBalancer(NewRoundRobinBalancer([]string{"s1"}), SheddingConfig{
+		Window:      time.Second,
+		Threshold:   0.1,
+		MinRequests: 10,
+	})
+
+	for i := 0; i < 5; i++ {
+		balancer.Report("s1", errors.New("boom"))
+	}
+	if f := balancer.ShedFraction(); f != 0 {
+		t.Errorf("ShedFraction() = %.3f below MinRequests, want 0", f)
+	}
+}
+
+func TestAdaptiveSheddingBalancer_RandSource(t *testing.T) {
+	// 相同的随机数源丢弃相同的请求
+	newBalancer := func() *AdaptiveSheddingBalancer {
+		b := NewAdaptiveSheddingBalancer(NewRoundRobinBalancer([]string{"s1"}), SheddingConfig{
+			Window:      10 * time.Second,
+			Threshold:   0.2,
+			MinRequests: 10,
+		}, WithClock(NewFakeClock(time.Unix(1_700_000_000, 0))), WithRandSource(rand.NewSource(42)))
+		for i := 0; i < 100; i++ {
+			var err error
+			if i%5 < 3 {
+				err = errors.New("boom")
+			}
+			b.Report("s1", err)
+		}
+		return b
+	}
+	b1, b2 := newBalancer(), newBalancer()
+	for i := 0; i < 100; i++ {
+		_, err1 := b1.NextE()
+		_, err2 := b2.NextE()
+		if err1 != err2 {
+			t.Fatalf("call %d: %v != %v", i, err1, err2)
+		}
+	}
+}
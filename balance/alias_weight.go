@@ -0,0 +1,130 @@
+package balance
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// aliasTable Vose 别名表，和服务器列表一起原子替换，Next 看到的总是一致的快照
+// empty 表示服务器列表本身为空，用来区分 ErrEmptyPool 和 ErrAllUnavailable
+type aliasTable struct {
+	addrs []string
+	prob  []float64
+	alias []int
+	empty bool
+}
+
+// AliasWeightBalancer
+// 基于别名法（alias method）的加权随机：构造时和 SetServers 时预计算概率表和别名表，
+// 之后每次选择只需要一次整数随机和一次浮点随机，O(1)，适合服务器数量很多的场景
+// 分布与 RandomWeightBalancer 相同，但不支持健康状态、探测等附加功能
+type AliasWeightBalancer struct {
+	table atomic.Pointer[aliasTable]
+	rng   *rand.Rand
+	lock  sync.Mutex
+}
+
+// NewAliasWeightBalancer 权重为0的服务器不参与选择，支持 WithRandSource
+// 权重按与 SetServers 相同的规则校验，servers 可以为空，此时 NextE 返回 ErrEmptyPool
+func NewAliasWeightBalancer(servers []*Server, opts ...Option) (*AliasWeightBalancer, error) {
+	if err := validateWeights(servers, maxWeight, maxTotalWeight); err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	b := &AliasWeightBalancer{
+		rng: rand.New(o.source),
+	}
+	b.table.Store(newAliasTable(servers))
+	return b, nil
+}
+
+// SetServers 按与 NewRandomWeightBalancerE 相同的规则校验权重，然后重新计算别名表并原子替换
+func (b *AliasWeightBalancer) SetServers(servers []*Server) error {
+	if err := validateWeights(servers, maxWeight, maxTotalWeight); err != nil {
+		return err
+	}
+	b.table.Store(newAliasTable(servers))
+	return nil
+}
+
+func (b *AliasWeightBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *AliasWeightBalancer) NextE() (string, error) {
+	t := b.table.Load()
+	if len(t.addrs) == 0 {
+		if t.empty {
+			return "", ErrEmptyPool
+		}
+		return "", ErrAllUnavailable
+	}
+
+	b.lock.Lock()
+	i := b.rng.Intn(len(t.addrs))
+	f := b.rng.Float64()
+	b.lock.Unlock()
+
+	if f < t.prob[i] {
+		return t.addrs[i], nil
+	}
+	return t.addrs[t.alias[i]], nil
+}
+
+// newAliasTable Vose 算法：把每个概率缩放为平均值为1，不足1的格子由超过1的服务器补齐
+func newAliasTable(servers []*Server) *aliasTable {
+	var (
+		addrs   []string
+		weights []float64
+		total   float64
+	)
+	for _, s := range servers {
+		if s.Weight > 0 {
+			addrs = append(addrs, s.Addr)
+			weights = append(weights, float64(s.Weight))
+			total += float64(s.Weight)
+		}
+	}
+	n := len(addrs)
+	t := &aliasTable{
+		addrs: addrs,
+		prob:  make([]float64, n),
+		alias: make([]int, n),
+		empty: len(servers) == 0,
+	}
+	if n == 0 {
+		return t
+	}
+
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small, large = small[:len(small)-1], large[:len(large)-1]
+
+		t.prob[s] = scaled[s]
+		t.alias[s] = l
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			small = append(small, l)
+		} else {
+			large = append(large, l)
+		}
+	}
+	// 剩下的格子理论上都正好为1，浮点误差也按1处理
+	for _, i := range slices.Concat(small, large) {
+		t.prob[i] = 1
+	}
+	return t
+}
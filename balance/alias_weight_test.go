@@ -0,0 +1,110 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestAliasBalancer(tb testing.TB, servers []*Server) *AliasWeightBalancer {
+	tb.Helper()
+	b, err := NewAliasWeightBalancer(servers)
+	if err != nil {
+		tb.Fatalf("NewAliasWeightBalancer() error = %v", err)
+	}
+	return b
+}
+
+func TestAliasWeightBalancer_Distribution(t *testing.T) {
+	balancer := newTestAliasBalancer(t, []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 20},
+		{Addr: "server3", Weight: 0},
+		{Addr: "server4", Weight: 70},
+	})
+
+	const iterations = 100000
+	results := make(map[string]int)
+	for i := 0; i < iterations; i++ {
+		results[balancer.Next()]++
+	}
+	want := map[string]float64{"server1": 0.1, "server2": 0.2, "server3": 0, "server4": 0.7}
+	for addr, share := range want {
+		got := float64(results[addr]) / iterations
+		if got < share-0.01 || got > share+0.01 {
+			t.Errorf("%s share = %.3f, want %.2f", addr, got, share)
+		}
+	}
+}
+
+func TestAliasWeightBalancer_SetServers(t *testing.T) {
+	balancer := newTestAliasBalancer(t, []*Server{{Addr: "old", Weight: 1}})
+	if err := balancer.SetServers([]*Server{{Addr: "a", Weight: 1}, {Addr: "b", Weight: 3}}); err != nil {
+		t.Fatalf("SetServers() error = %v", err)
+	}
+	results := make(map[string]int)
+	for i := 0; i < 40000; i++ {
+		results[balancer.Next()]++
+	}
+	if results["old"] != 0 {
+		t.Errorf("old server selected after SetServers")
+	}
+	if ratio := float64(results["b"]) / float64(results["a"]); ratio < 2.8 || ratio > 3.2 {
+		t.Errorf("b/a ratio = %.2f, want ~3", ratio)
+	}
+
+	if err := balancer.SetServers([]*Server{{Addr: "bad", Weight: -1}}); err == nil {
+		t.Error("SetServers() with negative weight should fail")
+	}
+}
+
+func TestAliasWeightBalancer_Errors(t *testing.T) {
+	if _, err := newTestAliasBalancer(t, nil).NextE(); err != ErrEmptyPool {
+		t.Errorf("empty: error = %v, want ErrEmptyPool", err)
+	}
+	if _, err := newTestAliasBalancer(t, []*Server{{Addr: "a", Weight: 0}}).NextE(); err != ErrAllUnavailable {
+		t.Errorf("zero weights: error = %v, want ErrAllUnavailable", err)
+	}
+
+	// 构造时与 SetServers 使用相同的校验
+	for _, servers := range [][]*Server{
+		{{Addr: "a", Weight: -1}},
+		{{Addr: "a", Weight: maxWeight + 1}},
+	} {
+		if _, err := NewAliasWeightBalancer(servers); err == nil {
+			t.Errorf("NewAliasWeightBalancer(%v) should fail", servers[0])
+		}
+	}
+
+	// 列表变空后 NextE 返回 ErrEmptyPool，与别名表一起替换
+	balancer := newTestAliasBalancer(t, []*Server{{Addr: "a", Weight: 1}})
+	if err := balancer.SetServers(nil); err != nil {
+		t.Fatalf("SetServers() error = %v", err)
+	}
+	if _, err := balancer.NextE(); err != ErrEmptyPool {
+		t.Errorf("after SetServers(nil): error = %v, want ErrEmptyPool", err)
+	}
+}
+
+func benchmarkServers(n int) []*Server {
+	servers := make([]*Server, n)
+	for i := range servers {
+		servers[i] = &Server{Addr: fmt.Sprintf("server%d", i), Weight: i%10 + 1}
+	}
+	return servers
+}
+
+func BenchmarkAliasWeightBalancer_1000(b *testing.B) {
+	balancer := newTestAliasBalancer(b, benchmarkServers(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		balancer.Next()
+	}
+}
+
+func BenchmarkRandomWeightBalancer_1000(b *testing.B) {
+	balancer := NewRandomWeightBalancer(benchmarkServers(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		balancer.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package balance
+
+import (
+	"sync"
+	"time"
+)
+
+type backoffState struct {
+	failures int
+	until    time.Time
+}
+
+// BackoffBalancer
+// 失败退避：服务器失败后在一段时间内不再被选中，连续失败时退避时间指数增长（有上限），成功后重置
+// 与直接剔除不同，服务器一直留在池中，退避结束后会再次被尝试
+type BackoffBalancer struct {
+	balancer   Balancer
+	servers    []string
+	base       time.Duration
+	maxBackoff time.Duration
+	clock      Clock
+	state      map[string]*backoffState
+	lock       sync.RWMutex
+}
+
+// NewBackoffBalancer servers 需要和 b 中的服务器一致；第 n 次连续失败退避 base*2^(n-1)，不超过 maxBackoff
+// 支持 WithClock
+func NewBackoffBalancer(b Balancer, servers []string, base, maxBackoff time.Duration, opts ...Option) *BackoffBalancer {
+	o := newOptions(opts)
+	return &BackoffBalancer{
+		balancer:   b,
+		servers:    append([]string(nil), servers...),
+		base:       base,
+		maxBackoff: maxBackoff,
+		clock:      o.clock,
+		state:      make(map[string]*backoffState),
+	}
+}
+
+// MarkFailure 记录一次失败，并延长退避时间
+func (b *BackoffBalancer) MarkFailure(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	st, ok := b.state[addr]
+	if !ok {
+		st = &backoffState{}
+		b.state[addr] = st
+	}
+	st.failures++
+
+	backoff := b.base
+	for i := 1; i < st.failures && backoff < b.maxBackoff; i++ {
+		backoff *= 2
+	}
+	st.until = b.clock.Now().Add(min(backoff, b.maxBackoff))
+}
+
+// MarkSuccess 成功后清除失败记录
+func (b *BackoffBalancer) MarkSuccess(addr string) {
+	b.lock.Lock()
+	delete(b.state, addr)
+	b.lock.Unlock()
+}
+
+// BackoffUntil 返回 addr 的退避截止时间，没有退避时返回零值
+func (b *BackoffBalancer) BackoffUntil(addr string) time.Time {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if st, ok := b.state[addr]; ok {
+		return st.until
+	}
+	return time.Time{}
+}
+
+func (b *BackoffBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *BackoffBalancer) NextE() (string, error) {
+	return nextAvailable(b.balancer, b.servers, b.available)
+}
+
+func (b *BackoffBalancer) available(addr string) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	st, ok := b.state[addr]
+	return !ok || !b.clock.Now().Before(st.until)
+}
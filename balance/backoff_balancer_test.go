@@ -0,0 +1,69 @@
+package balance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffBalancer_ExponentialWindow(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewBackoffBalancer(NewRoundRobinBalancer([]string{"s1", "s2"}), []string{"s1", "s2"}, time.Second, 5*time.Second, WithClock(clock))
+
+	// 每次失败后的退避窗口：1s, 2s, 4s, 5s（封顶）
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		balancer.MarkFailure("s1")
+		if got := balancer.BackoffUntil("s1").Sub(clock.Now()); got != w {
+			t.Errorf("failure %d: backoff = %v, want %v", i+1, got, w)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(); got != "s2" {
+			t.Fatalf("Next() = %v, want s2 while s1 backs off", got)
+		}
+	}
+
+	// 退避结束后重新参与选择
+	clock.Advance(5 * time.Second)
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		seen[balancer.Next()] = true
+	}
+	if !seen["s1"] {
+		t.Error("s1 should be retried after backoff expires")
+	}
+}
+
+func TestBackoffBalancer_SuccessResets(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewBackoffBalancer(NewRoundRobinBalancer([]string{"s1", "s2"}), []string{"s1", "s2"}, time.Second, time.Minute, WithClock(clock))
+
+	balancer.MarkFailure("s1")
+	balancer.MarkFailure("s1")
+	balancer.MarkFailure("s1")
+	balancer.MarkSuccess("s1")
+	if !balancer.BackoffUntil("s1").IsZero() {
+		t.Error("success should clear backoff")
+	}
+
+	// 重置后再次失败，从基础退避时间开始
+	balancer.MarkFailure("s1")
+	if got := balancer.BackoffUntil("s1").Sub(clock.Now()); got != time.Second {
+		t.Errorf("backoff after reset = %v, want 1s", got)
+	}
+}
+
+func TestBackoffBalancer_NonListerInner(t *testing.T) {
+	// 内部的负载均衡器不需要实现 ServerLister，服务器列表显式传入
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	inner := NewRoundRobinBalancer([]string{"s1", "s2"})
+	balancer := NewBackoffBalancer(balancerFunc(inner.Next), []string{"s1", "s2"}, time.Second, time.Minute, WithClock(clock))
+
+	balancer.MarkFailure("s1")
+	for i := 0; i < 4; i++ {
+		if got, err := balancer.NextE(); err != nil || got != "s2" {
+			t.Fatalf("NextE() = %v, %v, want s2", got, err)
+		}
+	}
+}
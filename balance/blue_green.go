@@ -0,0 +1,62 @@
+package balance
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Color 蓝绿发布中的一组服务器
+type Color int32
+
+const (
+	Blue Color = iota
+	Green
+)
+
+func (c Color) String() string {
+	switch c {
+	case Blue:
+		return "blue"
+	case Green:
+		return "green"
+	default:
+		return fmt.Sprintf("Color(%d)", int32(c))
+	}
+}
+
+// BlueGreenBalancer
+// 蓝绿发布：两组完整的服务器，所有流量只走当前激活的一组，切换是原子的、没有中间比例
+// 与 RampingCanaryBalancer 的逐步放量不同，这里是一次性切换，回滚同样是一次 Activate
+type BlueGreenBalancer struct {
+	blue   Balancer
+	green  Balancer
+	active atomic.Int32
+}
+
+// NewBlueGreenBalancer 初始激活 blue
+func NewBlueGreenBalancer(blue, green Balancer) *BlueGreenBalancer {
+	return &BlueGreenBalancer{
+		blue:  blue,
+		green: green,
+	}
+}
+
+// Activate 原子地切换所有后续请求到 color 对应的一组，未知的 color 会被忽略
+func (b *BlueGreenBalancer) Activate(color Color) {
+	if color != Blue && color != Green {
+		return
+	}
+	b.active.Store(int32(color))
+}
+
+// Active 当前激活的一组
+func (b *BlueGreenBalancer) Active() Color {
+	return Color(b.active.Load())
+}
+
+func (b *BlueGreenBalancer) Next() string {
+	if b.Active() == Green {
+		return b.green.Next()
+	}
+	return b.blue.Next()
+}
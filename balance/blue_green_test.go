@@ -0,0 +1,64 @@
+package balance
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func assertAllFrom(t *testing.T, b *BlueGreenBalancer, prefix string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if got := b.Next(); !strings.HasPrefix(got, prefix) {
+			t.Fatalf("Next() = %v, want only %s* servers", got, prefix)
+		}
+	}
+}
+
+func TestBlueGreenBalancer_Cutover(t *testing.T) {
+	balancer := NewBlueGreenBalancer(
+		NewRoundRobinBalancer([]string{"blue1", "blue2"}),
+		NewRoundRobinBalancer([]string{"green1", "green2"}),
+	)
+
+	if balancer.Active() != Blue {
+		t.Errorf("Active() = %v, want blue", balancer.Active())
+	}
+	assertAllFrom(t, balancer, "blue")
+
+	balancer.Activate(Green)
+	assertAllFrom(t, balancer, "green")
+
+	// 回滚立即生效
+	balancer.Activate(Blue)
+	assertAllFrom(t, balancer, "blue")
+
+	// 未知的 color 不改变当前状态
+	balancer.Activate(Color(7))
+	assertAllFrom(t, balancer, "blue")
+}
+
+func TestBlueGreenBalancer_ConcurrentSwitch(t *testing.T) {
+	balancer := NewBlueGreenBalancer(
+		NewRoundRobinBalancer([]string{"blue1"}),
+		NewRoundRobinBalancer([]string{"green1"}),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if got := balancer.Next(); got != "blue1" && got != "green1" {
+					t.Errorf("Next() = %v", got)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		balancer.Activate(Color(i % 2))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+package balance
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// BoundedBalancer
+// 限制每个服务器的在途请求数：Acquire 从轮询位置开始找第一个未满的服务器，全部满了时 ok 为 false
+// 与 WaitQueueBalancer 不同，满了不会排队等待；计数只用原子操作，不加锁
+type BoundedBalancer struct {
+	servers []string
+	limits  []int64 // 小于等于0表示不限制
+	active  []atomic.Int64
+	cursor  atomic.Uint64
+}
+
+// NewBoundedBalancer limits 为每个服务器的在途请求上限，不在 limits 中的服务器不限制
+func NewBoundedBalancer(servers []string, limits map[string]int) *BoundedBalancer {
+	b := &BoundedBalancer{
+		servers: slices.Clone(servers),
+		limits:  make([]int64, len(servers)),
+		active:  make([]atomic.Int64, len(servers)),
+	}
+	for i, addr := range b.servers {
+		b.limits[i] = int64(limits[addr])
+	}
+	return b
+}
+
+// Acquire 返回一个未满的服务器和归还名额的 release，请求结束后需要调用 release，重复调用是安全的
+// 没有服务器或全部满了时 ok 为 false
+func (b *BoundedBalancer) Acquire() (addr string, release func(), ok bool) {
+	n := uint64(len(b.servers))
+	if n == 0 {
+		return "", nil, false
+	}
+	start := b.cursor.Add(1) - 1
+	for k := uint64(0); k < n; k++ {
+		i := (start + k) % n
+		if b.tryAcquire(i) {
+			var released atomic.Bool
+			return b.servers[i], func() {
+				if released.CompareAndSwap(false, true) {
+					b.active[i].Add(-1)
+				}
+			}, true
+		}
+	}
+	return "", nil, false
+}
+
+// tryAcquire 未满时占用一个名额，CAS 保证并发下不会超过上限
+func (b *BoundedBalancer) tryAcquire(i uint64) bool {
+	limit := b.limits[i]
+	for {
+		cur := b.active[i].Load()
+		if limit > 0 && cur >= limit {
+			return false
+		}
+		if b.active[i].CompareAndSwap(cur, cur+1) {
+			return true
+		}
+	}
+}
+
+// Active 返回 addr 当前的在途请求数
+func (b *BoundedBalancer) Active(addr string) int {
+	if i := slices.Index(b.servers, addr); i >= 0 {
+		return int(b.active[i].Load())
+	}
+	return 0
+}
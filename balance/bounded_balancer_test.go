@@ -0,0 +1,94 @@
+package balance
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBoundedBalancer_SaturateAndRecover(t *testing.T) {
+	balancer := NewBoundedBalancer([]string{"s1", "s2", "s3"}, map[string]int{"s1": 1, "s2": 2, "s3": 3})
+
+	// 并发占满全部 6 个名额，多出来的请求被拒绝
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		releases []func()
+		refused  int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, release, ok := balancer.Acquire()
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				releases = append(releases, release)
+			} else {
+				refused++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(releases) != 6 || refused != 14 {
+		t.Fatalf("acquired %d, refused %d, want 6 and 14", len(releases), refused)
+	}
+	for addr, limit := range map[string]int{"s1": 1, "s2": 2, "s3": 3} {
+		if got := balancer.Active(addr); got != limit {
+			t.Errorf("Active(%s) = %d, want %d", addr, got, limit)
+		}
+	}
+	if _, _, ok := balancer.Acquire(); ok {
+		t.Error("Acquire() should fail while saturated")
+	}
+
+	// 归还后恢复，重复 release 不会多归还
+	releases[0]()
+	releases[0]()
+	if _, _, ok := balancer.Acquire(); !ok {
+		t.Error("Acquire() should succeed after a release")
+	}
+	if _, _, ok := balancer.Acquire(); ok {
+		t.Error("a second release of the same slot should be a no-op")
+	}
+	for _, release := range releases[1:] {
+		release()
+	}
+	if _, _, ok := balancer.Acquire(); !ok {
+		t.Error("Acquire() should succeed after releasing everything")
+	}
+}
+
+func TestBoundedBalancer_Concurrency(t *testing.T) {
+	limits := map[string]int{"s1": 2, "s2": 2}
+	balancer := NewBoundedBalancer([]string{"s1", "s2"}, limits)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				addr, release, ok := balancer.Acquire()
+				if !ok {
+					continue
+				}
+				if n := balancer.Active(addr); n > limits[addr] {
+					t.Errorf("Active(%s) = %d exceeds limit %d", addr, n, limits[addr])
+				}
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for addr := range limits {
+		if got := balancer.Active(addr); got != 0 {
+			t.Errorf("Active(%s) = %d after all releases, want 0", addr, got)
+		}
+	}
+	if _, _, ok := NewBoundedBalancer(nil, nil).Acquire(); ok {
+		t.Error("Acquire() on empty balancer should fail")
+	}
+}
@@ -0,0 +1,30 @@
+package balance
+
+// CapacityBalancer
+// 按后端主动上报的空闲容量（如空闲连接数）做加权随机，空闲越多的服务器分到越多新请求
+// 类似加权最少连接，但信号来自后端推送而不是本地计数；空闲容量为0的服务器会被跳过，直到重新上报
+// 选择算法与 QuotaBalancer 相同，区别只在信号的含义：容量随负载上下波动，而配额会逐渐耗尽
+type CapacityBalancer struct {
+	quota *QuotaBalancer
+}
+
+// NewCapacityBalancer 使用 Server.Weight 作为初始空闲容量，收到第一次上报前按它分配流量
+func NewCapacityBalancer(servers []*Server) *CapacityBalancer {
+	return &CapacityBalancer{
+		quota: NewQuotaBalancer(servers),
+	}
+}
+
+// ReportCapacity 更新 addr 当前的空闲容量，未知地址会被忽略
+func (c *CapacityBalancer) ReportCapacity(addr string, free int) {
+	c.quota.ReportQuota(addr, free)
+}
+
+func (c *CapacityBalancer) Next() string {
+	addr, _ := c.NextE()
+	return addr
+}
+
+func (c *CapacityBalancer) NextE() (string, error) {
+	return c.quota.NextE()
+}
@@ -0,0 +1,71 @@
+package balance
+
+import (
+	"testing"
+)
+
+func capacityShares(b *CapacityBalancer, iterations int) map[string]float64 {
+	counts := make(map[string]int)
+	for i := 0; i < iterations; i++ {
+		counts[b.Next()]++
+	}
+	shares := make(map[string]float64, len(counts))
+	for addr, n := range counts {
+		shares[addr] = float64(n) / float64(iterations)
+	}
+	return shares
+}
+
+func TestCapacityBalancer_TracksFreeCapacity(t *testing.T) {
+	balancer := NewCapacityBalancer([]*Server{
+		{Addr: "server1", Weight: 1},
+		{Addr: "server2", Weight: 1},
+	})
+	balancer.ReportCapacity("server1", 80)
+	balancer.ReportCapacity("server2", 20)
+
+	shares := capacityShares(balancer, 20000)
+	if s := shares["server1"]; s < 0.77 || s > 0.83 {
+		t.Errorf("server1 share = %.3f, want ~0.8", s)
+	}
+
+	// 容量变化后分布随之调整
+	balancer.ReportCapacity("server1", 10)
+	balancer.ReportCapacity("server2", 30)
+	shares = capacityShares(balancer, 20000)
+	if s := shares["server2"]; s < 0.72 || s > 0.78 {
+		t.Errorf("after update server2 share = %.3f, want ~0.75", s)
+	}
+}
+
+func TestCapacityBalancer_ZeroCapacityExcluded(t *testing.T) {
+	balancer := NewCapacityBalancer([]*Server{
+		{Addr: "server1", Weight: 5},
+		{Addr: "server2", Weight: 5},
+	})
+	balancer.ReportCapacity("server1", 0)
+
+	for i := 0; i < 1000; i++ {
+		if got := balancer.Next(); got != "server2" {
+			t.Fatalf("Next() = %v, want server2", got)
+		}
+	}
+
+	// 重新上报空闲容量后恢复
+	balancer.ReportCapacity("server1", 5)
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		seen[balancer.Next()] = true
+	}
+	if !seen["server1"] {
+		t.Error("server1 should be selected again after reporting capacity")
+	}
+}
+
+func TestCapacityBalancer_AllFull(t *testing.T) {
+	balancer := NewCapacityBalancer([]*Server{{Addr: "server1", Weight: 1}})
+	balancer.ReportCapacity("server1", 0)
+	if _, err := balancer.NextE(); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
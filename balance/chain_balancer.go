@@ -0,0 +1,41 @@
+package balance
+
+// ChainBalancer
+// 按顺序组合多个负载均衡器：依次尝试，返回第一个非空的地址
+// 适合主/备资源池，配合 HealthBalancer 等过滤包装器使用，主池全部被过滤掉时才会用到备池
+type ChainBalancer struct {
+	balancers []Balancer
+}
+
+func NewChainBalancer(balancers ...Balancer) *ChainBalancer {
+	return &ChainBalancer{
+		balancers: append([]Balancer(nil), balancers...),
+	}
+}
+
+func (c *ChainBalancer) Next() string {
+	addr, _ := c.NextWithFallback()
+	return addr
+}
+
+// NextE 没有任何负载均衡器时返回 ErrEmptyPool，全部返回空时返回 ErrAllUnavailable
+func (c *ChainBalancer) NextE() (string, error) {
+	if len(c.balancers) == 0 {
+		return "", ErrEmptyPool
+	}
+	addr, _ := c.NextWithFallback()
+	if addr == "" {
+		return "", ErrAllUnavailable
+	}
+	return addr, nil
+}
+
+// NextWithFallback fallback 为 true 表示不是由第一个负载均衡器选出的
+func (c *ChainBalancer) NextWithFallback() (string, bool) {
+	for i, b := range c.balancers {
+		if addr := b.Next(); addr != "" {
+			return addr, i > 0
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,43 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestChainBalancer_FallsThrough(t *testing.T) {
+	primary := NewHealthBalancer(NewRoundRobinBalancer([]string{"p1", "p2"}), []string{"p1", "p2"})
+	backup := NewRoundRobinBalancer([]string{"b1"})
+	balancer := NewChainBalancer(primary, backup)
+
+	// 主池可用时优先使用主池
+	for i := 0; i < 4; i++ {
+		addr, fallback := balancer.NextWithFallback()
+		if (addr != "p1" && addr != "p2") || fallback {
+			t.Errorf("NextWithFallback() = %v, %v, want primary server", addr, fallback)
+		}
+	}
+
+	// 主池全部被过滤掉后使用备池
+	primary.SetHealthy("p1", false)
+	primary.SetHealthy("p2", false)
+	addr, fallback := balancer.NextWithFallback()
+	if addr != "b1" || !fallback {
+		t.Errorf("NextWithFallback() = %v, %v, want b1, true", addr, fallback)
+	}
+
+	// 主池恢复后回到主池
+	primary.SetHealthy("p2", true)
+	if got := balancer.Next(); got != "p2" {
+		t.Errorf("Next() = %v, want p2", got)
+	}
+}
+
+func TestChainBalancer_Empty(t *testing.T) {
+	if _, err := NewChainBalancer().NextE(); err != ErrEmptyPool {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+	balancer := NewChainBalancer(NewRoundRobinBalancer(nil), NewRandomBalancer(nil))
+	if _, err := balancer.NextE(); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
@@ -0,0 +1,83 @@
+package balance
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState struct {
+	failures int // 连续失败次数
+	until    time.Time
+}
+
+// CircuitBreakerBalancer
+// 异常剔除：连续失败达到阈值的服务器在冷却时间内不再被选中，冷却结束后自动恢复
+// 与 BackoffBalancer 不同，偶发的失败不会影响选择，只有连续失败才会触发剔除
+type CircuitBreakerBalancer struct {
+	balancer  Balancer
+	servers   []string
+	threshold int
+	cooldown  time.Duration
+	clock     Clock
+	state     map[string]*breakerState
+	lock      sync.RWMutex
+}
+
+// NewCircuitBreakerBalancer servers 需要和 b 中的服务器一致；连续 threshold 次失败后剔除 cooldown，threshold 小于1时按1处理
+// 支持 WithClock
+func NewCircuitBreakerBalancer(b Balancer, servers []string, threshold int, cooldown time.Duration, opts ...Option) *CircuitBreakerBalancer {
+	o := newOptions(opts)
+	return &CircuitBreakerBalancer{
+		balancer:  b,
+		servers:   append([]string(nil), servers...),
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		clock:     o.clock,
+		state:     make(map[string]*breakerState),
+	}
+}
+
+// ReportSuccess 成功后清零连续失败次数
+func (c *CircuitBreakerBalancer) ReportSuccess(addr string) {
+	c.lock.Lock()
+	delete(c.state, addr)
+	c.lock.Unlock()
+}
+
+// ReportFailure 记录一次失败，连续失败达到阈值时剔除，并重新开始计数
+func (c *CircuitBreakerBalancer) ReportFailure(addr string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	st, ok := c.state[addr]
+	if !ok {
+		st = &breakerState{}
+		c.state[addr] = st
+	}
+	st.failures++
+	if st.failures >= c.threshold {
+		st.failures = 0
+		st.until = c.clock.Now().Add(c.cooldown)
+	}
+}
+
+// Ejected addr 当前是否处于剔除状态
+func (c *CircuitBreakerBalancer) Ejected(addr string) bool {
+	return !c.available(addr)
+}
+
+func (c *CircuitBreakerBalancer) Next() string {
+	addr, _ := c.NextE()
+	return addr
+}
+
+func (c *CircuitBreakerBalancer) NextE() (string, error) {
+	return nextAvailable(c.balancer, c.servers, c.available)
+}
+
+func (c *CircuitBreakerBalancer) available(addr string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	st, ok := c.state[addr]
+	return !ok || !c.clock.Now().Before(st.until)
+}
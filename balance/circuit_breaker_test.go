@@ -0,0 +1,71 @@
+package balance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerBalancer_TripAndReadmit(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewCircuitBreakerBalancer(NewRoundRobinBalancer([]string{"s1", "s2"}), []string{"s1", "s2"}, 3, 10*time.Second, WithClock(clock))
+
+	// 未达到阈值时不剔除
+	balancer.ReportFailure("s1")
+	balancer.ReportFailure("s1")
+	if balancer.Ejected("s1") {
+		t.Fatal("s1 ejected before reaching the threshold")
+	}
+
+	balancer.ReportFailure("s1")
+	if !balancer.Ejected("s1") {
+		t.Fatal("s1 should be ejected after 3 consecutive failures")
+	}
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(); got != "s2" {
+			t.Fatalf("Next() = %v, want s2 while s1 is ejected", got)
+		}
+	}
+
+	// 冷却结束后重新参与选择
+	clock.Advance(10 * time.Second)
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		seen[balancer.Next()] = true
+	}
+	if !seen["s1"] {
+		t.Error("s1 should be readmitted after the cooldown")
+	}
+}
+
+func TestCircuitBreakerBalancer_SuccessResets(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewCircuitBreakerBalancer(NewRoundRobinBalancer([]string{"s1", "s2"}), []string{"s1", "s2"}, 2, time.Minute, WithClock(clock))
+
+	// 失败之间夹着成功，不算连续失败
+	balancer.ReportFailure("s1")
+	balancer.ReportSuccess("s1")
+	balancer.ReportFailure("s1")
+	if balancer.Ejected("s1") {
+		t.Error("non-consecutive failures should not eject s1")
+	}
+
+	balancer.ReportFailure("s1")
+	balancer.ReportFailure("s2")
+	balancer.ReportFailure("s2")
+	if _, err := balancer.NextE(); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
+
+func TestCircuitBreakerBalancer_NonListerInner(t *testing.T) {
+	// 内部的负载均衡器不需要实现 ServerLister，服务器列表显式传入
+	inner := NewRoundRobinBalancer([]string{"s1", "s2"})
+	balancer := NewCircuitBreakerBalancer(balancerFunc(inner.Next), []string{"s1", "s2"}, 1, time.Minute)
+
+	balancer.ReportFailure("s1")
+	for i := 0; i < 4; i++ {
+		if got, err := balancer.NextE(); err != nil || got != "s2" {
+			t.Fatalf("NextE() = %v, %v, want s2", got, err)
+		}
+	}
+}
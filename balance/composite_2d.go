@@ -0,0 +1,42 @@
+package balance
+
+import (
+	"fmt"
+)
+
+// CompositeBalancer
+// 二维路由：第一维（如租户）确定服务器池，第二维（如分片键）在池内做一致性哈希
+// 同一个 (primary, secondary) 总是路由到同一台服务器，池内增删服务器时只有少量分片迁移
+type CompositeBalancer struct {
+	rings map[string]*hashRing
+}
+
+// NewCompositeBalancer pools 为第一维键到服务器池的映射，权重决定池内的分片占比
+// 每个池的虚拟节点数为总权重乘以 defaultVirtualNodes，不能超过 maxTotalWeight，构造哈希环之前校验
+func NewCompositeBalancer(pools map[string][]*Server) (*CompositeBalancer, error) {
+	for key, servers := range pools {
+		if err := validateWeights(servers, maxWeight, maxTotalWeight/defaultVirtualNodes); err != nil {
+			return nil, fmt.Errorf("pool %s: %w", key, err)
+		}
+	}
+	b := &CompositeBalancer{
+		rings: make(map[string]*hashRing, len(pools)),
+	}
+	for key, servers := range pools {
+		b.rings[key] = newHashRing(servers, defaultVirtualNodes)
+	}
+	return b, nil
+}
+
+// NextFor2D 未配置的第一维键返回 ErrEmptyPool，池内没有正权重的服务器时返回 ErrAllUnavailable
+func (b *CompositeBalancer) NextFor2D(primaryKey, secondaryKey string) (string, error) {
+	ring, ok := b.rings[primaryKey]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrEmptyPool, primaryKey)
+	}
+	addr := ring.get(secondaryKey)
+	if addr == "" {
+		return "", fmt.Errorf("%w: %s", ErrAllUnavailable, primaryKey)
+	}
+	return addr, nil
+}
@@ -0,0 +1,105 @@
+package balance
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestCompositeBalancer(t *testing.T) *CompositeBalancer {
+	t.Helper()
+	b, err := NewCompositeBalancer(map[string][]*Server{
+		"tenant-a": {{Addr: "a1", Weight: 1}, {Addr: "a2", Weight: 1}, {Addr: "a3", Weight: 2}},
+		"tenant-b": {{Addr: "b1", Weight: 1}, {Addr: "b2", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeBalancer() error = %v", err)
+	}
+	return b
+}
+
+func TestCompositeBalancer_Deterministic(t *testing.T) {
+	b1 := newTestCompositeBalancer(t)
+	b2 := newTestCompositeBalancer(t)
+
+	for i := 0; i < 1000; i++ {
+		shard := "shard-" + strconv.Itoa(i)
+		first, err := b1.NextFor2D("tenant-a", shard)
+		if err != nil {
+			t.Fatalf("NextFor2D() error = %v", err)
+		}
+		for j := 0; j < 3; j++ {
+			if got, _ := b1.NextFor2D("tenant-a", shard); got != first {
+				t.Fatalf("NextFor2D(tenant-a, %s) = %v, want %v", shard, got, first)
+			}
+		}
+		// 独立构造的实例结果也一致
+		if got, _ := b2.NextFor2D("tenant-a", shard); got != first {
+			t.Fatalf("new instance NextFor2D(tenant-a, %s) = %v, want %v", shard, got, first)
+		}
+	}
+}
+
+func TestCompositeBalancer_DisjointPools(t *testing.T) {
+	balancer := newTestCompositeBalancer(t)
+
+	poolA := map[string]bool{"a1": true, "a2": true, "a3": true}
+	poolB := map[string]bool{"b1": true, "b2": true}
+	seenA := make(map[string]int)
+	seenB := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		shard := "shard-" + strconv.Itoa(i)
+		a, _ := balancer.NextFor2D("tenant-a", shard)
+		b, _ := balancer.NextFor2D("tenant-b", shard)
+		if !poolA[a] {
+			t.Fatalf("tenant-a routed to %v outside its pool", a)
+		}
+		if !poolB[b] {
+			t.Fatalf("tenant-b routed to %v outside its pool", b)
+		}
+		seenA[a]++
+		seenB[b]++
+	}
+
+	// 所有服务器都分到分片，且 a3 的权重为 2，分片大约是 a1 的两倍
+	if len(seenA) != 3 || len(seenB) != 2 {
+		t.Errorf("expected all servers used, got %v %v", seenA, seenB)
+	}
+	if ratio := float64(seenA["a3"]) / float64(seenA["a1"]); ratio < 1.5 || ratio > 2.6 {
+		t.Errorf("a3/a1 shard ratio = %.2f, want ~2", ratio)
+	}
+}
+
+func TestCompositeBalancer_Errors(t *testing.T) {
+	balancer, err := NewCompositeBalancer(map[string][]*Server{
+		"tenant-a": {{Addr: "a1", Weight: 1}},
+		"drained":  {{Addr: "d1", Weight: 0}},
+	})
+	if err != nil {
+		t.Fatalf("NewCompositeBalancer() error = %v", err)
+	}
+
+	if _, err := balancer.NextFor2D("unknown", "shard"); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("unknown tenant error = %v, want ErrEmptyPool", err)
+	}
+	if _, err := balancer.NextFor2D("drained", "shard"); !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("drained tenant error = %v, want ErrAllUnavailable", err)
+	}
+	if got, err := balancer.NextFor2D("tenant-a", "shard"); err != nil || got != "a1" {
+		t.Errorf("NextFor2D() = %v, %v, want a1, nil", got, err)
+	}
+}
+
+func TestCompositeBalancer_InvalidWeights(t *testing.T) {
+	for name, servers := range map[string][]*Server{
+		"negative":       {{Addr: "a1", Weight: -1}},
+		"exceeds max":    {{Addr: "a1", Weight: maxWeight + 1}},
+		"ring too large": {{Addr: "a1", Weight: maxTotalWeight / defaultVirtualNodes}, {Addr: "a2", Weight: 1}},
+	} {
+		_, err := NewCompositeBalancer(map[string][]*Server{"tenant": servers})
+		if err == nil || !strings.Contains(err.Error(), "tenant") {
+			t.Errorf("%s: error = %v, want error naming the pool", name, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package balance
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// defaultVirtualNodes 默认每个服务器（每一单位权重）在哈希环上的虚拟节点数
+const defaultVirtualNodes = 150
+
+// ConsistentHashBalancer
+// 一致性哈希：相同的 key 总是落到同一台服务器，增删一台服务器时只有约 1/n 的 key 需要迁移
+// 哈希基于 FNV-1a，结果与进程、运行次数无关，适合缓存亲和
+type ConsistentHashBalancer struct {
+	ring *hashRing
+}
+
+// NewConsistentHashBalancer 虚拟节点数默认为 150，可以通过 WithVirtualNodes 调整
+func NewConsistentHashBalancer(servers []string, opts ...Option) *ConsistentHashBalancer {
+	o := newOptions(opts)
+	replicas := o.virtualNodes
+	if replicas <= 0 {
+		replicas = defaultVirtualNodes
+	}
+	nodes := make([]*Server, 0, len(servers))
+	for _, addr := range servers {
+		nodes = append(nodes, &Server{Addr: addr, Weight: 1})
+	}
+	return &ConsistentHashBalancer{
+		ring: newHashRing(nodes, replicas),
+	}
+}
+
+// NextForKey 没有服务器时返回空字符串
+func (b *ConsistentHashBalancer) NextForKey(key string) string {
+	return b.ring.get(key)
+}
+
+// hashRing 一致性哈希环，服务器的虚拟节点数与权重成正比
+type hashRing struct {
+	hashes []uint64
+	addrs  map[uint64]string
+}
+
+// newHashRing replicas 为每一单位权重的虚拟节点数
+func newHashRing(servers []*Server, replicas int) *hashRing {
+	r := &hashRing{addrs: make(map[uint64]string)}
+	for _, s := range servers {
+		for i := 0; i < s.Weight*replicas; i++ {
+			h := hashKey(s.Addr + "#" + strconv.Itoa(i))
+			// 哈希冲突时保留先加入的服务器
+			if _, ok := r.addrs[h]; ok {
+				continue
+			}
+			r.addrs[h] = s.Addr
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// get 返回顺时针方向第一个虚拟节点对应的服务器，环为空时返回空字符串
+func (r *hashRing) get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.addrs[r.hashes[i]]
+}
+
+// hashKey FNV-1a 对只有后缀不同的键分布较差，再经过一次 splitmix64 的混合
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,70 @@
+package balance
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestConsistentHashBalancer_SameKeySameServer(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	b1 := NewConsistentHashBalancer(servers)
+	b2 := NewConsistentHashBalancer(servers)
+
+	for i := 0; i < 1000; i++ {
+		key := "key-" + strconv.Itoa(i)
+		got := b1.NextForKey(key)
+		if got == "" {
+			t.Fatalf("NextForKey(%s) returned empty string", key)
+		}
+		if b1.NextForKey(key) != got || b2.NextForKey(key) != got {
+			t.Fatalf("NextForKey(%s) not stable", key)
+		}
+	}
+}
+
+func TestConsistentHashBalancer_RemoveServerRemapsFew(t *testing.T) {
+	servers := make([]string, 10)
+	for i := range servers {
+		servers[i] = "server-" + strconv.Itoa(i)
+	}
+	before := NewConsistentHashBalancer(servers)
+	after := NewConsistentHashBalancer(servers[1:])
+
+	const keys = 10000
+	kept := 0
+	for i := 0; i < keys; i++ {
+		key := "key-" + strconv.Itoa(i)
+		old := before.NextForKey(key)
+		if old == servers[0] {
+			continue
+		}
+		if after.NextForKey(key) == old {
+			kept++
+		}
+	}
+	// 只有原来落在被移除服务器上的 key 需要迁移
+	if ratio := float64(kept) / keys; ratio < 0.8 {
+		t.Errorf("only %.2f of keys kept their server, want >= 0.8", ratio)
+	}
+}
+
+func TestConsistentHashBalancer_Distribution(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := NewConsistentHashBalancer(servers, WithVirtualNodes(200))
+
+	counts := make(map[string]int)
+	for i := 0; i < 20000; i++ {
+		counts[balancer.NextForKey("key-"+strconv.Itoa(i))]++
+	}
+	for _, s := range servers {
+		if share := float64(counts[s]) / 20000; share < 0.18 || share > 0.32 {
+			t.Errorf("%s share = %.3f, want ~0.25", s, share)
+		}
+	}
+}
+
+func TestConsistentHashBalancer_Empty(t *testing.T) {
+	if got := NewConsistentHashBalancer(nil).NextForKey("k"); got != "" {
+		t.Errorf("NextForKey() = %v, want empty", got)
+	}
+}
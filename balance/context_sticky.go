@@ -0,0 +1,59 @@
+package balance
+
+import (
+	"context"
+	"sync"
+)
+
+type stickyTokenKey struct{}
+
+// stickyToken 保存同一条请求链上第一次选中的服务器
+type stickyToken struct {
+	mu   sync.Mutex
+	addr string
+}
+
+// WithStickyToken 在 ctx 中放入一个空的粘性令牌
+// 之后用该 ctx（及其派生 ctx）调用 ContextStickyBalancer.Next，都会返回第一次选中的服务器
+func WithStickyToken(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stickyTokenKey{}, &stickyToken{})
+}
+
+// StickyAddr 返回 ctx 中已经绑定的服务器，未绑定时 ok 为 false
+func StickyAddr(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(stickyTokenKey{}).(*stickyToken)
+	if !ok {
+		return "", false
+	}
+	token.mu.Lock()
+	defer token.mu.Unlock()
+	return token.addr, token.addr != ""
+}
+
+// ContextStickyBalancer
+// 基于 context 的请求链亲和：同一个令牌内只选择一次，后续复用
+// ctx 中没有令牌时，每次都会重新选择
+type ContextStickyBalancer struct {
+	balancer Balancer
+}
+
+func NewContextStickyBalancer(b Balancer) *ContextStickyBalancer {
+	return &ContextStickyBalancer{
+		balancer: b,
+	}
+}
+
+func (s *ContextStickyBalancer) Next(ctx context.Context) string {
+	token, ok := ctx.Value(stickyTokenKey{}).(*stickyToken)
+	if !ok {
+		return s.balancer.Next()
+	}
+
+	// 持锁选择，保证并发的第一次调用也只绑定一个服务器
+	token.mu.Lock()
+	defer token.mu.Unlock()
+	if token.addr == "" {
+		token.addr = s.balancer.Next()
+	}
+	return token.addr
+}
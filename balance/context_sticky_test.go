@@ -0,0 +1,55 @@
+package balance
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextStickyBalancer_SameContext(t *testing.T) {
+	balancer := NewContextStickyBalancer(NewRoundRobinBalancer([]string{"s1", "s2", "s3"}))
+	ctx := WithStickyToken(context.Background())
+
+	first := balancer.Next(ctx)
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(ctx); got != first {
+			t.Errorf("Next() = %v, want sticky %v", got, first)
+		}
+	}
+
+	addr, ok := StickyAddr(ctx)
+	if !ok || addr != first {
+		t.Errorf("StickyAddr() = %v, %v, want %v, true", addr, ok, first)
+	}
+}
+
+func TestContextStickyBalancer_FreshContext(t *testing.T) {
+	balancer := NewContextStickyBalancer(NewRoundRobinBalancer([]string{"s1", "s2", "s3"}))
+
+	ctx1 := WithStickyToken(context.Background())
+	ctx2 := WithStickyToken(context.Background())
+
+	got1 := balancer.Next(ctx1)
+	got2 := balancer.Next(ctx2)
+	if got1 == got2 {
+		t.Errorf("fresh context should re-select, both got %v", got1)
+	}
+	if balancer.Next(ctx1) != got1 || balancer.Next(ctx2) != got2 {
+		t.Errorf("each context should keep its own server")
+	}
+}
+
+func TestContextStickyBalancer_NoToken(t *testing.T) {
+	balancer := NewContextStickyBalancer(newOrderedRoundRobin([]string{"s1", "s2"}))
+
+	// 没有令牌时退化为普通轮询
+	want := []string{"s1", "s2", "s1"}
+	for _, w := range want {
+		if got := balancer.Next(context.Background()); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+
+	if _, ok := StickyAddr(context.Background()); ok {
+		t.Errorf("StickyAddr() should be false without token")
+	}
+}
@@ -0,0 +1,97 @@
+package balance
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+)
+
+// deterministicSeed 固定的洗牌种子，保证相同配置得到相同的序列
+const deterministicSeed = 20240101
+
+// DeterministicWeightedBalancer
+// 不依赖随机数的加权选择：构造时把权重展开成一张固定顺序（打散过）的调度表，
+// 第 n 次调用返回表中第 n%len 个服务器，输出只取决于调用次数，方便回放和集成测试
+type DeterministicWeightedBalancer struct {
+	schedule []string
+	err      error // 构造时权重超限的错误
+	index    uint64
+}
+
+// NewDeterministicWeightedBalancer 权重会先按最大公约数约分，权重小于等于0的服务器被忽略
+// 权重超出 maxWeight 或 maxTotalWeight 时不展开调度表，NextE 返回对应的错误
+func NewDeterministicWeightedBalancer(servers []*Server) Balancer {
+	schedule, err := expandWeights(servers)
+	rng := rand.New(rand.NewSource(deterministicSeed))
+	rng.Shuffle(len(schedule), func(i, j int) {
+		schedule[i], schedule[j] = schedule[j], schedule[i]
+	})
+	return &DeterministicWeightedBalancer{
+		schedule: schedule,
+		err:      err,
+	}
+}
+
+func (d *DeterministicWeightedBalancer) Next() string {
+	addr, _ := d.NextE()
+	return addr
+}
+
+func (d *DeterministicWeightedBalancer) NextE() (string, error) {
+	if d.err != nil {
+		return "", d.err
+	}
+	if len(d.schedule) == 0 {
+		return "", ErrAllUnavailable
+	}
+	n := atomic.AddUint64(&d.index, 1)
+	return d.schedule[(n-1)%uint64(len(d.schedule))], nil
+}
+
+// expandWeights 按最大公约数约分后把权重展开成序列，每个服务器连续出现 weight/gcd 次
+// 权重小于等于0的服务器被忽略；正权重超出 maxWeight 或总和超出 maxTotalWeight 时返回错误，不分配序列
+func expandWeights(servers []*Server) ([]string, error) {
+	var total int64
+	for _, s := range servers {
+		if s.Weight <= 0 {
+			continue
+		}
+		if int64(s.Weight) > maxWeight {
+			return nil, fmt.Errorf("server %s weight %d exceeds max %d", s.Addr, s.Weight, maxWeight)
+		}
+		// 单个权重不超过 maxWeight，累加到超出 maxTotalWeight 之前不会溢出
+		if total += int64(s.Weight); total > maxTotalWeight {
+			return nil, fmt.Errorf("total weight %d exceeds max %d", total, maxTotalWeight)
+		}
+	}
+
+	g := weightGCD(servers)
+	var seq []string
+	for _, s := range servers {
+		if s.Weight <= 0 {
+			continue
+		}
+		for i := 0; i < s.Weight/g; i++ {
+			seq = append(seq, s.Addr)
+		}
+	}
+	return seq, nil
+}
+
+// weightGCD 正权重的最大公约数，没有正权重时返回 1
+func weightGCD(servers []*Server) int {
+	g := 0
+	for _, s := range servers {
+		if s.Weight > 0 {
+			g = gcd(g, s.Weight)
+		}
+	}
+	return max(g, 1)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,68 @@
+package balance
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDeterministicWeightedBalancer_Replay(t *testing.T) {
+	newServers := func() []*Server {
+		return []*Server{
+			{Addr: "a", Weight: 50},
+			{Addr: "b", Weight: 30},
+			{Addr: "c", Weight: 20},
+		}
+	}
+	b1 := NewDeterministicWeightedBalancer(newServers())
+	b2 := NewDeterministicWeightedBalancer(newServers())
+
+	for i := 0; i < 100; i++ {
+		if got1, got2 := b1.Next(), b2.Next(); got1 != got2 {
+			t.Fatalf("call %d: %v != %v", i, got1, got2)
+		}
+	}
+}
+
+func TestDeterministicWeightedBalancer_ExactCycle(t *testing.T) {
+	balancer := NewDeterministicWeightedBalancer([]*Server{
+		{Addr: "a", Weight: 50},
+		{Addr: "b", Weight: 30},
+		{Addr: "c", Weight: 20},
+		{Addr: "d", Weight: 0},
+	})
+
+	// 约分后一个周期长度为 10
+	counts := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		counts[balancer.Next()]++
+	}
+	if counts["a"] != 5 || counts["b"] != 3 || counts["c"] != 2 || counts["d"] != 0 {
+		t.Errorf("unexpected counts over one cycle: %v", counts)
+	}
+}
+
+func TestDeterministicWeightedBalancer_Empty(t *testing.T) {
+	if got := NewDeterministicWeightedBalancer(nil).Next(); got != "" {
+		t.Errorf("Next() = %v, want empty", got)
+	}
+}
+
+func TestDeterministicWeightedBalancer_WeightLimits(t *testing.T) {
+	// 总和超限时不展开，哪怕约分后序列不长
+	var overTotal []*Server
+	for i := 0; i <= maxTotalWeight/maxWeight; i++ {
+		overTotal = append(overTotal, &Server{Addr: strconv.Itoa(i), Weight: maxWeight})
+	}
+	for name, servers := range map[string][]*Server{
+		"weight exceeds max": {{Addr: "a", Weight: maxWeight + 1}},
+		"total exceeds max":  overTotal,
+	} {
+		balancer := NewDeterministicWeightedBalancer(servers)
+		if _, err := balancer.(ErrorBalancer).NextE(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if n := len(balancer.(*DeterministicWeightedBalancer).schedule); n != 0 {
+			t.Errorf("%s: schedule length = %d, want 0", name, n)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package balance
+
+import (
+	"sync"
+)
+
+// DriftConfig 权重漂移检测的配置
+type DriftConfig struct {
+	Window    int     // 统计最近多少次选择
+	Threshold float64 // 实际占比与目标占比的最大允许偏差，比如 0.05 表示 5 个百分点
+	// OnDrift 某个服务器的偏差从正常变为超限时调用一次，恢复正常后才会再次触发
+	OnDrift func(addr string, observed, target float64)
+	// Compensate 为 true 时，检测到漂移后用当前最欠缺的服务器替代内部的选择，把分布拉回目标
+	// 只补偿内部负载均衡器最近 Window 次调用中返回过的服务器，被它过滤掉（比如不健康）的服务器不会被补偿选中
+	Compensate bool
+}
+
+// DriftMonitor
+// 比较最近 Window 次选择的实际占比和配置的目标权重，偏差超过阈值时回调告警，并可选地自动补偿
+type DriftMonitor struct {
+	balancer Balancer
+	cfg      DriftConfig
+	targets  map[string]float64
+	order    []string // 目标服务器的固定顺序，保证补偿结果确定
+
+	window   []string
+	pos      int
+	counts   map[string]int
+	drifting map[string]bool
+	calls    uint64            // 内部负载均衡器的调用次数
+	returned map[string]uint64 // 内部负载均衡器最后一次返回该服务器时的 calls
+	lock     sync.Mutex
+}
+
+func NewDriftMonitor(b Balancer, targets []*Server, cfg DriftConfig) *DriftMonitor {
+	d := &DriftMonitor{
+		balancer: b,
+		cfg:      cfg,
+		targets:  make(map[string]float64, len(targets)),
+		window:   make([]string, 0, max(cfg.Window, 1)),
+		counts:   make(map[string]int),
+		drifting: make(map[string]bool),
+		returned: make(map[string]uint64),
+	}
+	total := 0
+	for _, s := range targets {
+		if s.Weight > 0 {
+			total += s.Weight
+		}
+	}
+	for _, s := range targets {
+		if s.Weight > 0 && total > 0 {
+			d.targets[s.Addr] = float64(s.Weight) / float64(total)
+			d.order = append(d.order, s.Addr)
+		}
+	}
+	return d
+}
+
+func (d *DriftMonitor) Next() string {
+	addr := d.balancer.Next()
+	if addr == "" {
+		return addr
+	}
+
+	d.lock.Lock()
+	d.calls++
+	d.returned[addr] = d.calls
+	if d.cfg.Compensate && len(d.window) == cap(d.window) {
+		if starved := d.mostStarvedLocked(); starved != "" {
+			addr = starved
+		}
+	}
+	d.observeLocked(addr)
+	fired := d.checkLocked()
+	d.lock.Unlock()
+
+	// 回调在锁外执行
+	for _, f := range fired {
+		d.cfg.OnDrift(f.addr, f.observed, d.targets[f.addr])
+	}
+	return addr
+}
+
+// Shares 最近窗口内各服务器的实际占比
+func (d *DriftMonitor) Shares() map[string]float64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	shares := make(map[string]float64, len(d.counts))
+	for addr, c := range d.counts {
+		shares[addr] = float64(c) / float64(len(d.window))
+	}
+	return shares
+}
+
+type driftEvent struct {
+	addr     string
+	observed float64
+}
+
+func (d *DriftMonitor) observeLocked(addr string) {
+	if len(d.window) < cap(d.window) {
+		d.window = append(d.window, addr)
+	} else {
+		old := d.window[d.pos]
+		d.counts[old]--
+		if d.counts[old] == 0 {
+			delete(d.counts, old)
+		}
+		d.window[d.pos] = addr
+		d.pos = (d.pos + 1) % len(d.window)
+	}
+	d.counts[addr]++
+}
+
+// checkLocked 窗口填满后才开始检测，只在状态从正常变为漂移时产生事件
+func (d *DriftMonitor) checkLocked() []driftEvent {
+	if len(d.window) < cap(d.window) {
+		return nil
+	}
+	var fired []driftEvent
+	for _, addr := range d.order {
+		observed := float64(d.counts[addr]) / float64(len(d.window))
+		diff := observed - d.targets[addr]
+		drifting := diff > d.cfg.Threshold || diff < -d.cfg.Threshold
+		if drifting && !d.drifting[addr] && d.cfg.OnDrift != nil {
+			fired = append(fired, driftEvent{addr: addr, observed: observed})
+		}
+		d.drifting[addr] = drifting
+	}
+	return fired
+}
+
+// mostStarvedLocked 返回低于目标占比超过阈值最多的服务器，没有时返回空
+// 内部负载均衡器最近一个窗口内没有返回过的服务器可能已经不可用，不参与补偿
+func (d *DriftMonitor) mostStarvedLocked() string {
+	best, bestDiff := "", -d.cfg.Threshold
+	for _, addr := range d.order {
+		if last, ok := d.returned[addr]; !ok || d.calls-last >= uint64(cap(d.window)) {
+			continue
+		}
+		diff := float64(d.counts[addr])/float64(len(d.window)) - d.targets[addr]
+		if diff < bestDiff {
+			best, bestDiff = addr, diff
+		}
+	}
+	return best
+}
@@ -0,0 +1,113 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestDriftMonitor_FiresOnSkew(t *testing.T) {
+	var events []string
+	// 内部负载均衡器只会返回 a，与 1:1 的目标严重偏离
+	monitor := NewDriftMonitor(NewRoundRobinBalancer([]string{"a"}), []*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 1},
+	}, DriftConfig{
+		Window:    100,
+		Threshold: 0.1,
+		OnDrift: func(addr string, observed, target float64) {
+			events = append(events, addr)
+			if target != 0.5 {
+				t.Errorf("target = %.2f, want 0.5", target)
+			}
+		},
+	})
+
+	for i := 0; i < 500; i++ {
+		monitor.Next()
+	}
+
+	// 每个服务器只在进入漂移状态时触发一次
+	if len(events) != 2 {
+		t.Errorf("OnDrift fired %v, want once for a and b", events)
+	}
+	if share := monitor.Shares()["a"]; share != 1 {
+		t.Errorf("a share = %.2f, want 1", share)
+	}
+}
+
+func TestDriftMonitor_Compensates(t *testing.T) {
+	fired := 0
+	// 内部负载均衡器按 3:1 返回 a 和 b，目标为 1:1
+	calls := 0
+	inner := balancerFunc(func() string {
+		calls++
+		if calls%4 == 0 {
+			return "b"
+		}
+		return "a"
+	})
+	monitor := NewDriftMonitor(inner, []*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 1},
+	}, DriftConfig{
+		Window:     100,
+		Threshold:  0.1,
+		OnDrift:    func(addr string, observed, target float64) { fired++ },
+		Compensate: true,
+	})
+
+	for i := 0; i < 1000; i++ {
+		monitor.Next()
+	}
+
+	if fired == 0 {
+		t.Error("expected drift callback before compensation kicks in")
+	}
+	// 补偿把 b 的占比拉回到目标附近
+	if share := monitor.Shares()["b"]; share < 0.38 {
+		t.Errorf("b share = %.2f, want pulled back toward 0.5", share)
+	}
+}
+
+func TestDriftMonitor_CompensatesOnlyReturned(t *testing.T) {
+	// b 被内部负载均衡器过滤掉（比如不健康），不能因为占比不足就把流量补偿给它
+	health := NewHealthBalancer(NewRoundRobinBalancer([]string{"a", "b"}), []string{"a", "b"})
+	monitor := NewDriftMonitor(health, []*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 1},
+	}, DriftConfig{
+		Window:     100,
+		Threshold:  0.1,
+		Compensate: true,
+	})
+
+	for i := 0; i < 200; i++ {
+		monitor.Next()
+	}
+	health.SetHealthy("b", false)
+	// 摘除之前返回过的 b 在一个窗口之后不再被补偿
+	for i := 0; i < 100; i++ {
+		monitor.Next()
+	}
+	for i := 0; i < 500; i++ {
+		if got := monitor.Next(); got != "a" {
+			t.Fatalf("call %d: Next() = %v, want a while b is unhealthy", i, got)
+		}
+	}
+}
+
+func TestDriftMonitor_NoDrift(t *testing.T) {
+	monitor := NewDriftMonitor(NewRoundRobinBalancer([]string{"a", "b"}), []*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 1},
+	}, DriftConfig{
+		Window:    100,
+		Threshold: 0.05,
+		OnDrift: func(addr string, observed, target float64) {
+			t.Errorf("unexpected drift on %s: %.2f", addr, observed)
+		},
+	})
+
+	for i := 0; i < 1000; i++ {
+		monitor.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package balance
+
+import "fmt"
+
+// DuplicatePolicy 决定加权构造函数如何处理重复的服务器地址
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeep 保持原样，重复地址的权重会被重复计算（历史行为）
+	DuplicateKeep DuplicatePolicy = iota
+	// DuplicateMerge 合并重复地址，权重相加，保留第一次出现的位置和属性（Types、Tags）
+	DuplicateMerge
+	// DuplicateReject 遇到重复地址直接返回错误
+	DuplicateReject
+)
+
+// NewRandomWeightBalancerWithPolicy 按 policy 处理重复地址后再构造加权随机负载均衡器
+// 处理后的权重同样受 maxWeight 和 maxTotalWeight 限制，合并后超限时返回错误
+func NewRandomWeightBalancerWithPolicy(servers []*Server, policy DuplicatePolicy) (Balancer, error) {
+	deduped, err := dedupServers(servers, policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWeights(deduped, maxWeight, maxTotalWeight); err != nil {
+		return nil, err
+	}
+	return NewRandomWeightBalancer(deduped), nil
+}
+
+// dedupServers 返回处理后的新切片，不会修改调用方传入的 Server
+func dedupServers(servers []*Server, policy DuplicatePolicy) ([]*Server, error) {
+	if policy == DuplicateKeep {
+		return servers, nil
+	}
+
+	index := make(map[string]int, len(servers))
+	result := make([]*Server, 0, len(servers))
+	for _, s := range servers {
+		// 负权重合并后可能被抵消，合并之前检查
+		if s.Weight < 0 {
+			return nil, fmt.Errorf("server %s weight must not be negative, got: %d", s.Addr, s.Weight)
+		}
+		i, ok := index[s.Addr]
+		if !ok {
+			index[s.Addr] = len(result)
+			result = append(result, cloneServer(s))
+			continue
+		}
+		if policy == DuplicateReject {
+			return nil, fmt.Errorf("duplicate server address: %s", s.Addr)
+		}
+		merged, ok := addWeight(int64(result[i].Weight), int64(s.Weight))
+		if !ok {
+			return nil, fmt.Errorf("server %s merged weight overflows int64", s.Addr)
+		}
+		result[i].Weight = int(merged)
+	}
+	return result, nil
+}
+
+// checkDuplicates 任何地址出现超过一次时返回错误，错误信息中包含第一个重复的地址
+// 带 E 后缀的加权构造函数和平滑加权轮询构造时都会检查，重复地址几乎总是配置错误
+func checkDuplicates[T any](items []T, addr func(T) string) error {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		a := addr(item)
+		if seen[a] {
+			return fmt.Errorf("duplicate server address: %s", a)
+		}
+		seen[a] = true
+	}
+	return nil
+}
+
+func serverAddr(s *Server) string {
+	return s.Addr
+}
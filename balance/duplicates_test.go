@@ -0,0 +1,135 @@
+package balance
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRandomWeightBalancerWithPolicy_Merge(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 20},
+		{Addr: "server1", Weight: 10},
+	}
+	balancer, err := NewRandomWeightBalancerWithPolicy(servers, DuplicateMerge)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	merged := balancer.(*RandomWeightBalancer).servers.Load().servers
+	if len(merged) != 2 || merged[0].Addr != "server1" || merged[0].Weight != 20 {
+		t.Errorf("expected server1 merged to weight 20, got %v", merged[0])
+	}
+	// 不能修改调用方的数据
+	if servers[0].Weight != 10 {
+		t.Errorf("input server mutated: weight = %d", servers[0].Weight)
+	}
+
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.Next()]++
+	}
+	ratio := float64(results["server1"]) / float64(results["server2"])
+	if ratio < 0.8 || ratio > 1.2 {
+		t.Errorf("Expected server1/server2 ratio around 1.0, got %.2f", ratio)
+	}
+}
+
+func TestRandomWeightBalancerWithPolicy_Reject(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server1", Weight: 5},
+	}
+	_, err := NewRandomWeightBalancerWithPolicy(servers, DuplicateReject)
+	if err == nil {
+		t.Fatal("expected error for duplicate address")
+	}
+	if !strings.Contains(err.Error(), "server1") {
+		t.Errorf("error should name the duplicate address, got %v", err)
+	}
+}
+
+func TestRandomWeightBalancerWithPolicy_Keep(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server1", Weight: 5},
+	}
+	balancer, err := NewRandomWeightBalancerWithPolicy(servers, DuplicateKeep)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(balancer.(*RandomWeightBalancer).servers.Load().servers); got != 2 {
+		t.Errorf("keep policy should leave %d entries, got %d", 2, got)
+	}
+}
+
+func TestConstructorsRejectDuplicates(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 20},
+		{Addr: "server1", Weight: 5},
+	}
+	_, errE := NewRandomWeightBalancerE(servers)
+	_, errLimits := NewRandomWeightBalancerWithLimits(servers, maxWeight, maxTotalWeight)
+	_, errSmooth := NewSmoothRRBalancerE([]*Node{NewNode("a", 1), NewNode("b", 1), NewNode("b", 2)})
+
+	for name, tt := range map[string]struct {
+		err  error
+		addr string
+	}{
+		"NewRandomWeightBalancerE":          {errE, "server1"},
+		"NewRandomWeightBalancerWithLimits": {errLimits, "server1"},
+		"NewSmoothRRBalancerE":              {errSmooth, "b"},
+	} {
+		want := "duplicate server address: " + tt.addr
+		if tt.err == nil || tt.err.Error() != want {
+			t.Errorf("%s: error = %v, want %q", name, tt.err, want)
+		}
+	}
+}
+
+func TestDedupServers_KeepsAttributes(t *testing.T) {
+	servers := []*Server{
+		{Addr: "a", Weight: 1, Types: []string{"read"}, Tags: map[string]string{"zone": "x"}},
+		{Addr: "a", Weight: 2, Tags: map[string]string{"zone": "y"}},
+	}
+	deduped, err := dedupServers(servers, DuplicateMerge)
+	if err != nil {
+		t.Fatalf("dedupServers() error = %v", err)
+	}
+	if len(deduped) != 1 || deduped[0].Weight != 3 {
+		t.Fatalf("dedupServers() = %v, want a single server with weight 3", deduped)
+	}
+	// 属性以第一次出现的为准，并且是副本
+	got := deduped[0]
+	if len(got.Types) != 1 || got.Types[0] != "read" || got.Tags["zone"] != "x" {
+		t.Errorf("merged server = %+v, want attributes of the first occurrence", got)
+	}
+	got.Types[0] = "write"
+	got.Tags["zone"] = "z"
+	if servers[0].Types[0] != "read" || servers[0].Tags["zone"] != "x" {
+		t.Error("dedupServers shares Types or Tags with the input")
+	}
+}
+
+func TestRandomWeightBalancerWithPolicy_MergedLimits(t *testing.T) {
+	for name, servers := range map[string][]*Server{
+		"merged weight exceeds max": {
+			{Addr: "server1", Weight: maxWeight},
+			{Addr: "server1", Weight: 1},
+		},
+		"negative weight hidden by merge": {
+			{Addr: "server1", Weight: 10},
+			{Addr: "server1", Weight: -5},
+		},
+		"merged weight overflows": {
+			{Addr: "server1", Weight: math.MaxInt64},
+			{Addr: "server1", Weight: 1},
+		},
+	} {
+		if _, err := NewRandomWeightBalancerWithPolicy(servers, DuplicateMerge); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestEmptyPoolContract 带 E 后缀的构造函数对空输入统一返回 ErrEmptyPool，
+// 运行中变空时 Next 返回空值，NextE 返回 ErrEmptyPool
+func TestEmptyPoolContract(t *testing.T) {
+	constructors := map[string]func() error{
+		"RoundRobin": func() error {
+			_, err := NewRoundRobinBalancerE(nil)
+			return err
+		},
+		"Random": func() error {
+			_, err := NewRandomBalancerE([]string{})
+			return err
+		},
+		"RandomWeight": func() error {
+			_, err := NewRandomWeightBalancerE(nil)
+			return err
+		},
+		"RandomWeightWithLimits": func() error {
+			_, err := NewRandomWeightBalancerWithLimits(nil, maxWeight, maxTotalWeight)
+			return err
+		},
+		"SmoothRR": func() error {
+			_, err := NewSmoothRRBalancerE(nil)
+			return err
+		},
+	}
+	for name, construct := range constructors {
+		if err := construct(); !errors.Is(err, ErrEmptyPool) {
+			t.Errorf("%s: constructor error = %v, want ErrEmptyPool", name, err)
+		}
+	}
+
+	rr, err := NewRoundRobinBalancerE([]string{"a"})
+	if err != nil {
+		t.Fatalf("NewRoundRobinBalancerE() error = %v", err)
+	}
+	rr.(*RoundRobinBalancer).RemoveServer("a")
+
+	weighted, err := NewRandomWeightBalancerE([]*Server{{Addr: "a", Weight: 1}})
+	if err != nil {
+		t.Fatalf("NewRandomWeightBalancerE() error = %v", err)
+	}
+	if err := weighted.(*RandomWeightBalancer).SetServers(nil); err != nil {
+		t.Fatalf("SetServers(nil) error = %v", err)
+	}
+
+	for name, b := range map[string]ErrorBalancer{
+		"RoundRobin":   rr.(ErrorBalancer),
+		"RandomWeight": weighted.(ErrorBalancer),
+	} {
+		if got := b.Next(); got != "" {
+			t.Errorf("%s: Next() = %v after emptying, want empty string", name, got)
+		}
+		if _, err := b.NextE(); err != ErrEmptyPool {
+			t.Errorf("%s: NextE() error = %v after emptying, want ErrEmptyPool", name, err)
+		}
+	}
+
+	smooth, err := NewSmoothRRBalancerE([]*Node{NewNode("a", 1)})
+	if err != nil {
+		t.Fatalf("NewSmoothRRBalancerE() error = %v", err)
+	}
+	smooth.RemoveServer("a")
+	if node := smooth.Next(context.Background()); node != nil {
+		t.Errorf("SmoothRR: Next() = %v after emptying, want nil", node.Server())
+	}
+	if _, err := smooth.NextE(context.Background()); err != ErrEmptyPool {
+		t.Errorf("SmoothRR: NextE() error = %v after emptying, want ErrEmptyPool", err)
+	}
+}
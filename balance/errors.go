@@ -0,0 +1,48 @@
+package balance
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrEmptyPool 服务器列表本身为空，通常是配置问题
+	// 统一约定：带 E 后缀的构造函数（NewRoundRobinBalancerE、NewRandomBalancerE、NewRandomWeightBalancerE、
+	// NewSmoothRRBalancerE 等）在输入为空时返回包装了 ErrEmptyPool 的错误；
+	// 运行中列表变空（RemoveServer、SetServers）不算错误，Next 返回空值，NextE 返回 ErrEmptyPool
+	ErrEmptyPool = errors.New("server pool is empty")
+	// ErrAllUnavailable 列表不为空，但所有服务器都被过滤掉了（不健康、摘除、权重为0等），通常是故障
+	ErrAllUnavailable = errors.New("all servers are unavailable")
+	// ErrInsufficientQuorum 可用服务器数量低于法定数量
+	ErrInsufficientQuorum = errors.New("insufficient healthy servers for quorum")
+	// ErrQueueFull 等待队列已满
+	ErrQueueFull = errors.New("wait queue is full")
+	// ErrShedding 整体错误率过高，主动丢弃了本次请求
+	ErrShedding = errors.New("request shed due to high error rate")
+	// ErrNoServerForType 没有服务器支持该请求类型
+	ErrNoServerForType = errors.New("no server supports request type")
+	// ErrClosed 负载均衡器已经关闭
+	ErrClosed = errors.New("balancer is closed")
+	// ErrWeightOverflow 总权重超出 int64 范围，无法按权重选择
+	ErrWeightOverflow = errors.New("total weight overflows int64")
+)
+
+// ErrorBalancer 能区分选择失败原因的负载均衡器
+// Next 在失败时返回空字符串，NextE 返回具体的错误
+type ErrorBalancer interface {
+	Balancer
+	NextE() (string, error)
+}
+
+// ClosableBalancer 带后台任务（健康检查、定时器等）的负载均衡器
+// Close 停止所有后台任务，之后 Next 返回空字符串，NextE 返回 ErrClosed；重复 Close 是安全的
+type ClosableBalancer interface {
+	Balancer
+	io.Closer
+}
+
+// 带后台任务的负载均衡器都需要实现 ClosableBalancer
+var (
+	_ ClosableBalancer = (*TCPHealthCheckedBalancer)(nil)
+	_ ClosableBalancer = (*SRVBalancer)(nil)
+)
@@ -0,0 +1,49 @@
+package balance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNextE_EmptyPool(t *testing.T) {
+	balancers := map[string]ErrorBalancer{
+		"round_robin":   NewRoundRobinBalancer(nil).(ErrorBalancer),
+		"random":        NewRandomBalancer(nil).(ErrorBalancer),
+		"random_weight": NewRandomWeightBalancer(nil).(ErrorBalancer),
+	}
+
+	for name, b := range balancers {
+		addr, err := b.NextE()
+		if !errors.Is(err, ErrEmptyPool) {
+			t.Errorf("%s: NextE() error = %v, want ErrEmptyPool", name, err)
+		}
+		if addr != "" {
+			t.Errorf("%s: NextE() addr = %v, want empty", name, addr)
+		}
+	}
+}
+
+func TestNextE_AllUnavailable(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 0},
+		{Addr: "server2", Weight: 0},
+	}
+	balancer := NewRandomWeightBalancer(servers).(ErrorBalancer)
+
+	_, err := balancer.NextE()
+	if !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+	if errors.Is(err, ErrEmptyPool) {
+		t.Errorf("all filtered out must not be reported as empty pool")
+	}
+}
+
+func TestNextE_Success(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{{Addr: "server1", Weight: 1}}).(ErrorBalancer)
+
+	addr, err := balancer.NextE()
+	if err != nil || addr != "server1" {
+		t.Errorf("NextE() = %v, %v, want server1, nil", addr, err)
+	}
+}
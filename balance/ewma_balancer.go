@@ -0,0 +1,71 @@
+package balance
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	ewmaAlpha        = 0.3         // 新样本的权重
+	ewmaErrorPenalty = time.Second // 失败的请求额外计入的耗时
+)
+
+// EWMABalancer
+// 选择延迟指数加权移动平均（EWMA）最低的服务器
+// 请求结果通过 ReportOutcome 上报，同一份数据既用于算法，也转发给 MetricsSink
+// 没有样本的服务器视为最快，保证新节点会被探测到
+type EWMABalancer struct {
+	servers []string
+	ewma    map[string]float64 // 纳秒
+	sink    MetricsSink
+	lock    sync.Mutex
+}
+
+// NewEWMABalancer sink 可以为 nil
+func NewEWMABalancer(servers []string, sink MetricsSink) *EWMABalancer {
+	return &EWMABalancer{
+		servers: append([]string(nil), servers...),
+		ewma:    make(map[string]float64, len(servers)),
+		sink:    sink,
+	}
+}
+
+func (e *EWMABalancer) Next() string {
+	e.lock.Lock()
+	var (
+		best      string
+		bestScore float64
+	)
+	for _, addr := range e.servers {
+		score := e.ewma[addr]
+		if best == "" || score < bestScore {
+			best, bestScore = addr, score
+		}
+	}
+	e.lock.Unlock()
+
+	if best != "" && e.sink != nil {
+		e.sink.RecordSelection(best)
+	}
+	return best
+}
+
+// ReportOutcome 上报一次请求的结果，失败的请求会额外加上惩罚耗时
+func (e *EWMABalancer) ReportOutcome(addr string, rtt time.Duration, err error) {
+	sample := float64(rtt)
+	if err != nil {
+		sample += float64(ewmaErrorPenalty)
+	}
+
+	e.lock.Lock()
+	if old, ok := e.ewma[addr]; ok {
+		e.ewma[addr] = ewmaAlpha*sample + (1-ewmaAlpha)*old
+	} else {
+		e.ewma[addr] = sample
+	}
+	e.lock.Unlock()
+
+	if e.sink != nil {
+		e.sink.RecordOutcome(addr, rtt, err)
+	}
+}
@@ -0,0 +1,75 @@
+package balance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSink struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (f *fakeSink) RecordSelection(addr string) {
+	f.mu.Lock()
+	f.events = append(f.events, "select "+addr)
+	f.mu.Unlock()
+}
+
+func (f *fakeSink) RecordOutcome(addr string, rtt time.Duration, err error) {
+	f.mu.Lock()
+	f.events = append(f.events, fmt.Sprintf("outcome %s %v %v", addr, rtt, err != nil))
+	f.mu.Unlock()
+}
+
+func TestEWMABalancer_SinkEvents(t *testing.T) {
+	sink := &fakeSink{}
+	balancer := NewEWMABalancer([]string{"a", "b"}, sink)
+
+	addr := balancer.Next()
+	balancer.ReportOutcome(addr, 10*time.Millisecond, nil)
+	addr = balancer.Next()
+	balancer.ReportOutcome(addr, 20*time.Millisecond, errors.New("boom"))
+
+	want := []string{
+		"select a",
+		"outcome a 10ms false",
+		"select b",
+		"outcome b 20ms true",
+	}
+	if len(sink.events) != len(want) {
+		t.Fatalf("events = %v, want %v", sink.events, want)
+	}
+	for i := range want {
+		if sink.events[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, sink.events[i], want[i])
+		}
+	}
+}
+
+func TestEWMABalancer_PrefersLowLatency(t *testing.T) {
+	balancer := NewEWMABalancer([]string{"slow", "fast"}, nil)
+	balancer.ReportOutcome("slow", 100*time.Millisecond, nil)
+	balancer.ReportOutcome("fast", 10*time.Millisecond, nil)
+
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(); got != "fast" {
+			t.Fatalf("Next() = %v, want fast", got)
+		}
+	}
+
+	// 失败会被惩罚
+	balancer.ReportOutcome("fast", 10*time.Millisecond, errors.New("timeout"))
+	if got := balancer.Next(); got != "slow" {
+		t.Errorf("Next() = %v, want slow after failures on fast", got)
+	}
+}
+
+func TestEWMABalancer_Empty(t *testing.T) {
+	if got := NewEWMABalancer(nil, &fakeSink{}).Next(); got != "" {
+		t.Errorf("Next() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,39 @@
+package balance
+
+import (
+	"fmt"
+)
+
+// FailoverBalancer
+// 包装任意负载均衡器，跳过 healthy 判定为故障的服务器，最多尝试 maxAttempts 次
+// 与 HealthBalancer 不同，它不需要知道服务器列表，健康状态完全由调用方的判定函数决定
+type FailoverBalancer struct {
+	balancer    Balancer
+	healthy     func(addr string) bool
+	maxAttempts int
+}
+
+// NewFailoverBalancer maxAttempts 小于1时按1处理
+func NewFailoverBalancer(b Balancer, healthy func(addr string) bool, maxAttempts int) *FailoverBalancer {
+	return &FailoverBalancer{
+		balancer:    b,
+		healthy:     healthy,
+		maxAttempts: max(maxAttempts, 1),
+	}
+}
+
+func (f *FailoverBalancer) Next() string {
+	addr, _ := f.NextHealthy()
+	return addr
+}
+
+// NextHealthy 尝试次数用完仍没有找到健康的服务器时返回 ErrAllUnavailable
+func (f *FailoverBalancer) NextHealthy() (string, error) {
+	for i := 0; i < f.maxAttempts; i++ {
+		addr := f.balancer.Next()
+		if addr != "" && f.healthy(addr) {
+			return addr, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no healthy server after %d attempts", ErrAllUnavailable, f.maxAttempts)
+}
@@ -0,0 +1,46 @@
+package balance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFailoverBalancer_SkipsFailed(t *testing.T) {
+	failed := map[string]bool{"s1": true, "s2": true}
+	inner := NewRoundRobinBalancer([]string{"s1", "s2", "s3"})
+	balancer := NewFailoverBalancer(inner, func(addr string) bool { return !failed[addr] }, 3)
+
+	for i := 0; i < 10; i++ {
+		addr, err := balancer.NextHealthy()
+		if err != nil || addr != "s3" {
+			t.Fatalf("NextHealthy() = %v, %v, want s3, nil", addr, err)
+		}
+	}
+}
+
+func TestFailoverBalancer_AttemptBudget(t *testing.T) {
+	calls := 0
+	inner := newOrderedRoundRobin([]string{"s1", "s2", "s3"})
+	counting := balancerFunc(func() string {
+		calls++
+		return inner.Next()
+	})
+	// s3 排在第三个，只允许尝试两次时找不到
+	balancer := NewFailoverBalancer(counting, func(addr string) bool { return addr == "s3" }, 2)
+
+	_, err := balancer.NextHealthy()
+	if !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("NextHealthy() error = %v, want ErrAllUnavailable", err)
+	}
+	if calls != 2 {
+		t.Errorf("inner Next called %d times, want 2", calls)
+	}
+	if got := balancer.Next(); got != "s3" {
+		t.Errorf("Next() = %v, want s3", got)
+	}
+}
+
+// balancerFunc 把函数适配为 Balancer
+type balancerFunc func() string
+
+func (f balancerFunc) Next() string { return f() }
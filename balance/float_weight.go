@@ -0,0 +1,47 @@
+package balance
+
+import (
+	"fmt"
+	"math"
+)
+
+// FloatServer 使用小数权重的服务器
+type FloatServer struct {
+	Addr   string
+	Weight float64
+}
+
+// NewRandomWeightBalancerFromFloat 将小数权重乘以 scale（比如 1000 表示精确到 0.001）取整后，
+// 复用整数加权随机的实现。缩放后的权重同样受 maxWeight 和 maxTotalWeight 限制
+func NewRandomWeightBalancerFromFloat(servers []FloatServer, scale int64, opts ...Option) (Balancer, error) {
+	if scale <= 0 {
+		return nil, fmt.Errorf("scale must be positive, got: %d", scale)
+	}
+	if len(servers) == 0 {
+		return nil, ErrEmptyPool
+	}
+	if err := checkDuplicates(servers, func(s FloatServer) string { return s.Addr }); err != nil {
+		return nil, err
+	}
+
+	scaled := make([]*Server, 0, len(servers))
+	for _, s := range servers {
+		if math.IsNaN(s.Weight) || math.IsInf(s.Weight, 0) || s.Weight < 0 {
+			return nil, fmt.Errorf("server %s weight must be a non-negative number, got: %v", s.Addr, s.Weight)
+		}
+		w := math.Round(s.Weight * float64(scale))
+		if w > maxWeight {
+			return nil, fmt.Errorf("server %s scaled weight %.0f exceeds max %d", s.Addr, w, maxWeight)
+		}
+		// 正权重缩放后变成0，说明精度不够，直接报错而不是悄悄丢掉这个服务器
+		if s.Weight > 0 && w == 0 {
+			return nil, fmt.Errorf("server %s weight %v is too small for scale %d", s.Addr, s.Weight, scale)
+		}
+		scaled = append(scaled, &Server{Addr: s.Addr, Weight: int(w)})
+	}
+
+	if err := validateWeights(scaled, maxWeight, maxTotalWeight); err != nil {
+		return nil, err
+	}
+	return NewRandomWeightBalancer(scaled, opts...), nil
+}
@@ -0,0 +1,59 @@
+package balance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandomWeightBalancerFromFloat_Distribution(t *testing.T) {
+	balancer, err := NewRandomWeightBalancerFromFloat([]FloatServer{
+		{Addr: "a", Weight: 0.25},
+		{Addr: "b", Weight: 0.75},
+	}, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	scaled := balancer.(*RandomWeightBalancer).servers.Load().servers
+	if scaled[0].Weight != 250 || scaled[1].Weight != 750 {
+		t.Errorf("scaled weights = %d, %d, want 250, 750", scaled[0].Weight, scaled[1].Weight)
+	}
+
+	iterations := 100000
+	results := make(map[string]int)
+	for i := 0; i < iterations; i++ {
+		results[balancer.Next()]++
+	}
+	share := float64(results["a"]) / float64(iterations)
+	if share < 0.24 || share > 0.26 {
+		t.Errorf("a share = %.3f, want ~0.25", share)
+	}
+}
+
+func TestRandomWeightBalancerFromFloat_Limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []FloatServer
+		scale   int64
+	}{
+		{"scaled weight exceeds max", []FloatServer{{Addr: "a", Weight: 2}}, maxWeight},
+		{"scaled total exceeds max", []FloatServer{
+			{Addr: "a", Weight: 0.9}, {Addr: "b", Weight: 0.9}, {Addr: "c", Weight: 0.9},
+			{Addr: "d", Weight: 0.9}, {Addr: "e", Weight: 0.9}, {Addr: "f", Weight: 0.9},
+			{Addr: "g", Weight: 0.9}, {Addr: "h", Weight: 0.9}, {Addr: "i", Weight: 0.9},
+			{Addr: "j", Weight: 0.9}, {Addr: "k", Weight: 0.9}, {Addr: "l", Weight: 0.9},
+		}, maxWeight},
+		{"negative", []FloatServer{{Addr: "a", Weight: -0.5}}, 1000},
+		{"nan", []FloatServer{{Addr: "a", Weight: math.NaN()}}, 1000},
+		{"too small", []FloatServer{{Addr: "a", Weight: 0.0001}}, 1000},
+		{"invalid scale", []FloatServer{{Addr: "a", Weight: 1}}, 0},
+		{"empty", nil, 1000},
+		{"duplicate", []FloatServer{{Addr: "a", Weight: 0.5}, {Addr: "a", Weight: 0.5}}, 1000},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewRandomWeightBalancerFromFloat(tt.servers, tt.scale); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package balance
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// GenericBalancer 泛型负载均衡器，直接在任意类型上做选择（如 []*grpc.ClientConn），
+// 不需要再维护地址到连接对象的映射
+// 基于字符串地址的 Balancer 保持不变，RoundRobinBalancer/RandomBalancer 内部就是 RoundRobin[string]/Random[string]
+type GenericBalancer[T any] interface {
+	Next() T
+}
+
+// RoundRobin 泛型轮询，元素列表采用写时复制，Next 不需要加锁
+type RoundRobin[T any] struct {
+	items atomic.Pointer[[]T]
+	index uint64
+}
+
+// NewRoundRobin 复制一份 items
+func NewRoundRobin[T any](items []T) *RoundRobin[T] {
+	r := &RoundRobin[T]{}
+	r.store(slices.Clone(items))
+	return r
+}
+
+// Next 没有元素时返回零值
+func (r *RoundRobin[T]) Next() T {
+	v, _ := r.NextOK()
+	return v
+}
+
+// NextOK 没有元素时返回零值和 false
+func (r *RoundRobin[T]) NextOK() (T, bool) {
+	// 只读取一次列表，并发的修改不会导致下标越界
+	items := r.load()
+	if len(items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return items[r.nextIndex(items)], true
+}
+
+// nextIndex 推进轮询位置，返回在 items 中的下标，调用方保证 items 不为空
+func (r *RoundRobin[T]) nextIndex(items []T) int {
+	// 1. 原子递增索引值（保证并发安全）
+	// 注意：atomic.AddUint64 返回的是增加后的新值
+	newVal := atomic.AddUint64(&r.index, 1)
+
+	// 2. 对列表长度取模，实现循环轮询，减 1 是为了从 0 开始计数
+	return int((newVal - 1) % uint64(len(items)))
+}
+
+// nextN 一次推进 n 个位置，按轮询顺序返回接下来的 n 个元素，调用方保证 0 < n <= len(items)
+func (r *RoundRobin[T]) nextN(items []T, n int) []T {
+	start := atomic.AddUint64(&r.index, uint64(n)) - uint64(n)
+	out := make([]T, n)
+	for i := 0; i < n; i++ {
+		out[i] = items[(start+uint64(i))%uint64(len(items))]
+	}
+	return out
+}
+
+// Items 返回元素列表的副本
+func (r *RoundRobin[T]) Items() []T {
+	return slices.Clone(r.load())
+}
+
+// Len 返回元素数量
+func (r *RoundRobin[T]) Len() int {
+	return len(r.load())
+}
+
+func (r *RoundRobin[T]) load() []T {
+	return *r.items.Load()
+}
+
+func (r *RoundRobin[T]) store(items []T) {
+	r.items.Store(&items)
+}
+
+// Random 泛型随机，随机源故障时退化为确定性轮询
+type Random[T any] struct {
+	items    []T
+	rng      *rand.Rand
+	mu       sync.Mutex
+	fallback rngFallback
+}
+
+// NewRandom 复制一份 items，支持 WithRandSource
+func NewRandom[T any](items []T, opts ...Option) *Random[T] {
+	o := newOptions(opts)
+	return newRandom(slices.Clone(items), o.source)
+}
+
+func newRandom[T any](items []T, src rand.Source) *Random[T] {
+	return &Random[T]{
+		items: items,
+		rng:   rand.New(src),
+	}
+}
+
+// Next 没有元素时返回零值
+func (r *Random[T]) Next() T {
+	v, _ := r.NextOK()
+	return v
+}
+
+// NextOK 没有元素时返回零值和 false
+func (r *Random[T]) NextOK() (T, bool) {
+	if len(r.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	_, v, _ := r.pick()
+	return v, true
+}
+
+// Items 返回元素列表的副本
+func (r *Random[T]) Items() []T {
+	return slices.Clone(r.items)
+}
+
+// Len 返回元素数量
+func (r *Random[T]) Len() int {
+	return len(r.items)
+}
+
+// pick 调用方保证 items 不为空，返回选中的下标和元素；随机源故障时 rngOK 为 false，返回确定性轮询的结果
+func (r *Random[T]) pick() (i int, v T, rngOK bool) {
+	idx, ok := r.intn(int64(len(r.items)))
+	return int(idx), r.items[idx], ok
+}
+
+// intn 返回 [0, n) 中的随机数；随机源故障时 rngOK 为 false，返回确定性轮询的结果
+func (r *Random[T]) intn(n int64) (v int64, rngOK bool) {
+	v, ok := safeInt63n(&r.mu, r.rng, n)
+	if !ok {
+		v = r.fallback.next(n)
+	}
+	return v, ok
+}
+
+// sample 不放回地随机抽取 n 个元素（部分 Fisher-Yates），调用方保证 0 < n <= len(items)
+func (r *Random[T]) sample(n int) []T {
+	perm := make([]int, len(r.items))
+	for i := range perm {
+		perm[i] = i
+	}
+	out := make([]T, n)
+	for i := 0; i < n; i++ {
+		j, _ := r.intn(int64(len(perm) - i))
+		k := i + int(j)
+		perm[i], perm[k] = perm[k], perm[i]
+		out[i] = r.items[perm[i]]
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package balance
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type testConn struct {
+	addr string
+	id   int
+}
+
+func TestRoundRobin_Generic(t *testing.T) {
+	conns := []*testConn{{"s1", 1}, {"s2", 2}, {"s3", 3}}
+	var generic GenericBalancer[*testConn] = NewRoundRobin(conns)
+	strings := newOrderedRoundRobin([]string{"s1", "s2", "s3"})
+
+	// 与字符串版本的序列完全一致
+	for i := 0; i < 9; i++ {
+		c, addr := generic.Next(), strings.Next()
+		if c.addr != addr {
+			t.Fatalf("call %d: generic = %v, string = %v", i, c.addr, addr)
+		}
+	}
+
+	if _, ok := NewRoundRobin[*testConn](nil).NextOK(); ok {
+		t.Error("NextOK() on empty should be false")
+	}
+	if got := NewRoundRobin[testConn](nil).Next(); got != (testConn{}) {
+		t.Errorf("Next() on empty = %v, want zero value", got)
+	}
+}
+
+func TestRandom_Generic(t *testing.T) {
+	conns := []testConn{{"s1", 1}, {"s2", 2}, {"s3", 3}}
+	generic := NewRandom(conns, WithRandSource(rand.NewSource(7)))
+	strings := NewRandomBalancerWithSeed([]string{"s1", "s2", "s3"}, 7)
+
+	// 相同种子下与字符串版本的序列一致，分布自然也一致
+	counts := make(map[int]int)
+	for i := 0; i < 3000; i++ {
+		c := generic.Next()
+		if addr := strings.Next(); c.addr != addr {
+			t.Fatalf("call %d: generic = %v, string = %v", i, c.addr, addr)
+		}
+		counts[c.id]++
+	}
+	for id := 1; id <= 3; id++ {
+		if counts[id] < 900 || counts[id] > 1100 {
+			t.Errorf("conn %d selected %d times, want ~1000", id, counts[id])
+		}
+	}
+}
+
+func TestRandom_GenericRNGFallback(t *testing.T) {
+	generic := NewRandom([]int{10, 20}, WithRandSource(failingSource{}))
+	want := []int{10, 20, 10}
+	for _, w := range want {
+		if got := generic.Next(); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestWithGuaranteedProbe_EveryWindow(t *testing.T) {
+	const everyN = 20
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "main", Weight: 10000},
+		{Addr: "canary1", Weight: 1},
+		{Addr: "canary2", Weight: 1},
+	}, WithGuaranteedProbe([]string{"canary1", "canary2"}, everyN))
+
+	seq := make([]string, 2000)
+	for i := range seq {
+		seq[i] = balancer.Next()
+	}
+
+	// 任意连续 everyN 次选择都包含每个探测服务器
+	for start := 0; start+everyN <= len(seq); start++ {
+		seen := make(map[string]bool)
+		for _, addr := range seq[start : start+everyN] {
+			seen[addr] = true
+		}
+		if !seen["canary1"] || !seen["canary2"] {
+			t.Fatalf("window starting at %d misses a probe: %v", start, seq[start:start+everyN])
+		}
+	}
+
+	// 其余选择按权重进行
+	main := 0
+	for _, addr := range seq {
+		if addr == "main" {
+			main++
+		}
+	}
+	if share := float64(main) / float64(len(seq)); share < 0.88 {
+		t.Errorf("main share = %.3f, want ~0.9", share)
+	}
+}
+
+func TestWithGuaranteedProbe_SmallWindow(t *testing.T) {
+	// everyN 小于探测服务器数量时，按服务器数量轮流探测
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 1},
+	}, WithGuaranteedProbe([]string{"a", "b"}, 1))
+
+	want := []string{"a", "b", "a", "b"}
+	for _, w := range want {
+		if got := balancer.Next(); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+}
+
+func TestWithGuaranteedProbe_RemovedOrDisabled(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "main", Weight: 1},
+		{Addr: "canary", Weight: 1},
+	}, WithGuaranteedProbe([]string{"canary"}, 2)).(*RandomWeightBalancer)
+
+	// 探测地址被移除或权重置为0后，不再占用探测位置
+	for _, servers := range [][]*Server{
+		{{Addr: "main", Weight: 1}},
+		{{Addr: "main", Weight: 1}, {Addr: "canary", Weight: 0}},
+	} {
+		if err := balancer.SetServers(servers); err != nil {
+			t.Fatalf("SetServers() error = %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			if got := balancer.Next(); got != "main" {
+				t.Fatalf("Next() = %v, want main after canary is gone (%v)", got, servers)
+			}
+		}
+	}
+}
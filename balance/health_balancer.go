@@ -0,0 +1,149 @@
+package balance
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AvailabilityReporter 能报告当前可用服务器数量的负载均衡器
+type AvailabilityReporter interface {
+	Available() int
+}
+
+// ReportingBalancer 能报告可用服务器数量的负载均衡器，比如 HealthBalancer
+type ReportingBalancer interface {
+	Balancer
+	AvailabilityReporter
+}
+
+// HealthBalancer
+// 健康过滤包装器：跳过不健康或已摘除（drain）的服务器
+// 健康状态由调用方维护，内部的负载均衡器不需要感知
+type HealthBalancer struct {
+	balancer  Balancer
+	servers   []string
+	unhealthy map[string]bool
+	drained   map[string]bool
+	override  DecisionOverride
+	last      atomic.Pointer[[]string]
+	lock      sync.RWMutex
+}
+
+// NewHealthBalancer servers 需要和 b 中的服务器一致，初始全部健康
+func NewHealthBalancer(b Balancer, servers []string, opts ...Option) *HealthBalancer {
+	o := newOptions(opts)
+	return &HealthBalancer{
+		balancer:  b,
+		servers:   append([]string(nil), servers...),
+		unhealthy: make(map[string]bool),
+		drained:   make(map[string]bool),
+		override:  o.override,
+	}
+}
+
+func (h *HealthBalancer) SetHealthy(addr string, healthy bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if healthy {
+		delete(h.unhealthy, addr)
+	} else {
+		h.unhealthy[addr] = true
+	}
+}
+
+// Drain 摘除服务器，不再接收新流量
+func (h *HealthBalancer) Drain(addr string) {
+	h.lock.Lock()
+	h.drained[addr] = true
+	h.lock.Unlock()
+}
+
+func (h *HealthBalancer) Undrain(addr string) {
+	h.lock.Lock()
+	delete(h.drained, addr)
+	h.lock.Unlock()
+}
+
+// IsAvailable 健康且没有被摘除
+func (h *HealthBalancer) IsAvailable(addr string) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.available(addr)
+}
+
+// Available 当前可用的服务器数量
+func (h *HealthBalancer) Available() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	n := 0
+	for _, addr := range h.servers {
+		if h.available(addr) {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *HealthBalancer) Next() string {
+	addr, _ := h.NextE()
+	return addr
+}
+
+func (h *HealthBalancer) NextE() (string, error) {
+	// 候选集是当前可用的服务器
+	candidates := h.candidates()
+	h.last.Store(&candidates)
+	if addr, ok := applyOverride(h.override, func() []string {
+		return append([]string(nil), candidates...)
+	}); ok {
+		return addr, nil
+	}
+	return nextAvailable(h.balancer, h.servers, h.IsAvailable)
+}
+
+// LastCandidates 最近一次 Next 过滤后的候选服务器，用于排查某个服务器为什么（没）被选中
+// 并发调用时只是一个粗略的诊断信息
+func (h *HealthBalancer) LastCandidates() []string {
+	last := h.last.Load()
+	if last == nil {
+		return nil
+	}
+	return append([]string(nil), (*last)...)
+}
+
+func (h *HealthBalancer) candidates() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	candidates := make([]string, 0, len(h.servers))
+	for _, addr := range h.servers {
+		if h.available(addr) {
+			candidates = append(candidates, addr)
+		}
+	}
+	return candidates
+}
+
+func (h *HealthBalancer) available(addr string) bool {
+	return !h.unhealthy[addr] && !h.drained[addr]
+}
+
+// nextAvailable 从 b 中选择一个满足 available 的服务器，供各种过滤包装器复用
+// 最多尝试 len(servers) 次，轮询类的内部算法一定能在一圈内找到；
+// 随机类算法可能一直抽中不可用的服务器，兜底按顺序找一个
+func nextAvailable(b Balancer, servers []string, available func(addr string) bool) (string, error) {
+	if len(servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	for i := 0; i < len(servers); i++ {
+		addr := b.Next()
+		if addr != "" && available(addr) {
+			return addr, nil
+		}
+	}
+	for _, addr := range servers {
+		if available(addr) {
+			return addr, nil
+		}
+	}
+	return "", ErrAllUnavailable
+}
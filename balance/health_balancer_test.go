@@ -0,0 +1,80 @@
+package balance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHealthBalancer_SkipsUnavailable(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	balancer := NewHealthBalancer(NewRoundRobinBalancer(servers), servers)
+	balancer.SetHealthy("s1", false)
+	balancer.Drain("s3")
+
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(); got != "s2" {
+			t.Fatalf("Next() = %v, want s2", got)
+		}
+	}
+	if got := balancer.Available(); got != 1 {
+		t.Errorf("Available() = %d, want 1", got)
+	}
+
+	balancer.SetHealthy("s1", true)
+	balancer.Undrain("s3")
+	if got := balancer.Available(); got != 3 {
+		t.Errorf("Available() = %d, want 3", got)
+	}
+}
+
+func TestHealthBalancer_RandomFallback(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := NewHealthBalancer(NewRandomBalancer(servers), servers)
+	balancer.SetHealthy("s1", false)
+	balancer.SetHealthy("s2", false)
+	balancer.SetHealthy("s3", false)
+
+	for i := 0; i < 100; i++ {
+		if got := balancer.Next(); got != "s4" {
+			t.Fatalf("Next() = %v, want s4", got)
+		}
+	}
+}
+
+func TestHealthBalancer_Errors(t *testing.T) {
+	if _, err := NewHealthBalancer(NewRoundRobinBalancer(nil), nil).NextE(); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+
+	servers := []string{"s1", "s2"}
+	balancer := NewHealthBalancer(NewRoundRobinBalancer(servers), servers)
+	balancer.SetHealthy("s1", false)
+	balancer.Drain("s2")
+	if _, err := balancer.NextE(); !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
+
+func TestHealthBalancer_LastCandidates(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	balancer := NewHealthBalancer(NewRoundRobinBalancer(servers), servers)
+
+	if got := balancer.LastCandidates(); got != nil {
+		t.Errorf("LastCandidates() = %v before any Next, want nil", got)
+	}
+
+	balancer.SetHealthy("s1", false)
+	balancer.Drain("s3")
+	balancer.Next()
+
+	got := balancer.LastCandidates()
+	if len(got) != 1 || got[0] != "s2" {
+		t.Errorf("LastCandidates() = %v, want [s2]", got)
+	}
+
+	// 返回的是副本
+	got[0] = "modified"
+	if balancer.LastCandidates()[0] != "s2" {
+		t.Error("LastCandidates() should return a copy")
+	}
+}
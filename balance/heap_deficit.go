@@ -0,0 +1,92 @@
+package balance
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// deficitEntry 服务器在堆中的条目
+// 下一次应被服务的虚拟时间为 next/weight，值越小说明欠账（deficit）越多
+type deficitEntry struct {
+	addr   string
+	weight int64
+	next   int64
+}
+
+type deficitHeap []*deficitEntry
+
+func (h deficitHeap) Len() int { return len(h) }
+
+// Less 用交叉相乘比较 a.next/a.weight < b.next/b.weight，避免浮点误差
+// 虚拟时间相同时，按地址排序保证结果确定
+func (h deficitHeap) Less(i, j int) bool {
+	l, r := h[i].next*h[j].weight, h[j].next*h[i].weight
+	if l != r {
+		return l < r
+	}
+	return h[i].addr < h[j].addr
+}
+
+func (h deficitHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *deficitHeap) Push(x any) { *h = append(*h, x.(*deficitEntry)) }
+
+func (h *deficitHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// HeapDeficitBalancer
+// 基于最小堆的精确比例调度：每次取出欠账最多的服务器，服务后重新入堆
+// 每个周期（总权重次选择）内，每个服务器被选中的次数严格等于它的权重，单次选择 O(log n)
+type HeapDeficitBalancer struct {
+	heap   deficitHeap
+	total  int64
+	served int64
+	lock   sync.Mutex
+}
+
+// NewHeapDeficitBalancer 权重为0的服务器会被忽略
+// 权重按与 NewRandomWeightBalancerE 相同的规则校验，保证 next*weight 的交叉相乘不会溢出
+func NewHeapDeficitBalancer(servers []*Server) (*HeapDeficitBalancer, error) {
+	if err := validateWeights(servers, maxWeight, maxTotalWeight); err != nil {
+		return nil, err
+	}
+	b := &HeapDeficitBalancer{}
+	for _, s := range servers {
+		if s.Weight == 0 {
+			continue
+		}
+		b.heap = append(b.heap, &deficitEntry{addr: s.Addr, weight: int64(s.Weight), next: 1})
+		b.total += int64(s.Weight)
+	}
+	heap.Init(&b.heap)
+	return b, nil
+}
+
+func (b *HeapDeficitBalancer) Next() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.heap) == 0 {
+		return ""
+	}
+
+	top := b.heap[0]
+	top.next++
+	heap.Fix(&b.heap, 0)
+
+	// 一个完整周期结束时所有服务器的欠账都刚好还清，重置计数防止溢出
+	b.served++
+	if b.served == b.total {
+		b.served = 0
+		for _, e := range b.heap {
+			e.next = 1
+		}
+		heap.Init(&b.heap)
+	}
+	return top.addr
+}
@@ -0,0 +1,94 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestHeapDeficit(t testing.TB, servers []*Server) *HeapDeficitBalancer {
+	t.Helper()
+	b, err := NewHeapDeficitBalancer(servers)
+	if err != nil {
+		t.Fatalf("NewHeapDeficitBalancer() error = %v", err)
+	}
+	return b
+}
+
+func TestHeapDeficitBalancer_ExactCycle(t *testing.T) {
+	balancer := newTestHeapDeficit(t, []*Server{
+		{Addr: "a", Weight: 5},
+		{Addr: "b", Weight: 3},
+		{Addr: "c", Weight: 2},
+		{Addr: "d", Weight: 0},
+	})
+
+	// 连续多个周期，每个周期都严格按权重分配
+	for cycle := 0; cycle < 5; cycle++ {
+		counts := make(map[string]int)
+		for i := 0; i < 10; i++ {
+			counts[balancer.Next()]++
+		}
+		if counts["a"] != 5 || counts["b"] != 3 || counts["c"] != 2 || counts["d"] != 0 {
+			t.Errorf("cycle %d: unexpected counts %v", cycle, counts)
+		}
+	}
+}
+
+func TestHeapDeficitBalancer_LowBurst(t *testing.T) {
+	balancer := newTestHeapDeficit(t, []*Server{
+		{Addr: "a", Weight: 4},
+		{Addr: "b", Weight: 1},
+	})
+
+	maxConsecutive, consecutive := 0, 0
+	last := ""
+	for i := 0; i < 50; i++ {
+		got := balancer.Next()
+		if got == last {
+			consecutive++
+		} else {
+			consecutive = 1
+			last = got
+		}
+		maxConsecutive = max(maxConsecutive, consecutive)
+	}
+	if maxConsecutive > 4 {
+		t.Errorf("max consecutive = %d, want <= 4", maxConsecutive)
+	}
+}
+
+func TestHeapDeficitBalancer_Empty(t *testing.T) {
+	if got := newTestHeapDeficit(t, nil).Next(); got != "" {
+		t.Errorf("Next() = %v, want empty", got)
+	}
+}
+
+func TestHeapDeficitBalancer_InvalidWeights(t *testing.T) {
+	// 总权重超过 maxTotalWeight
+	overTotal := make([]*Server, maxTotalWeight/maxWeight+1)
+	for i := range overTotal {
+		overTotal[i] = &Server{Addr: fmt.Sprintf("server%d", i), Weight: maxWeight}
+	}
+	for _, servers := range [][]*Server{
+		{{Addr: "a", Weight: -1}},
+		{{Addr: "a", Weight: maxWeight + 1}},
+		overTotal,
+	} {
+		if _, err := NewHeapDeficitBalancer(servers); err == nil {
+			t.Errorf("NewHeapDeficitBalancer(%v) error = nil, want error", servers)
+		}
+	}
+}
+
+func BenchmarkHeapDeficitBalancer_10k(b *testing.B) {
+	servers := make([]*Server, 10000)
+	for i := range servers {
+		servers[i] = &Server{Addr: fmt.Sprintf("server%d", i), Weight: i%10 + 1}
+	}
+	balancer := newTestHeapDeficit(b, servers)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		balancer.Next()
+	}
+}
@@ -0,0 +1,162 @@
+package balance
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// GroupWeightMode 决定分层负载均衡中分组权重的来源
+type GroupWeightMode int
+
+const (
+	// GroupWeightStatic 使用配置的 Group.Weight，分组内没有健康服务器时权重为0
+	GroupWeightStatic GroupWeightMode = iota
+	// GroupWeightHealthyCount 分组权重等于分组内健康服务器的数量，成员或健康状态变化时自动更新
+	GroupWeightHealthyCount
+)
+
+// Group 一组服务器，比如一个机房或一个集群
+type Group struct {
+	Name    string
+	Weight  int
+	Servers []string
+}
+
+type hierGroup struct {
+	name    string
+	weight  int
+	servers []string
+	index   uint64
+}
+
+// HierarchicalBalancer
+// 两级负载均衡：先按分组权重随机选一个分组，再在分组内的健康服务器之间轮询
+type HierarchicalBalancer struct {
+	groups    []*hierGroup
+	mode      GroupWeightMode
+	unhealthy map[string]bool
+	effective []*Server // 缓存的分组有效权重，Addr 为分组名
+	rng       *rand.Rand
+	fallback  rngFallback
+	lock      sync.Mutex
+}
+
+// NewHierarchicalBalancer GroupWeightStatic 模式下分组权重按与 NewRandomWeightBalancerE 相同的规则校验
+func NewHierarchicalBalancer(groups []Group, mode GroupWeightMode) (*HierarchicalBalancer, error) {
+	if mode == GroupWeightStatic {
+		weights := make([]*Server, 0, len(groups))
+		for _, g := range groups {
+			weights = append(weights, &Server{Addr: g.Name, Weight: g.Weight})
+		}
+		if err := validateWeights(weights, maxWeight, maxTotalWeight); err != nil {
+			return nil, fmt.Errorf("group weights: %w", err)
+		}
+	}
+	b := &HierarchicalBalancer{
+		mode:      mode,
+		unhealthy: make(map[string]bool),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, g := range groups {
+		b.groups = append(b.groups, &hierGroup{
+			name:    g.Name,
+			weight:  g.Weight,
+			servers: append([]string(nil), g.Servers...),
+		})
+	}
+	b.recompute()
+	return b, nil
+}
+
+func (b *HierarchicalBalancer) SetHealthy(addr string, healthy bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if healthy {
+		delete(b.unhealthy, addr)
+	} else {
+		b.unhealthy[addr] = true
+	}
+	b.recompute()
+}
+
+// AddServer 向分组中添加服务器，分组不存在时返回 false
+func (b *HierarchicalBalancer) AddServer(group, addr string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, g := range b.groups {
+		if g.name == group {
+			g.servers = append(g.servers, addr)
+			b.recompute()
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveServer 从所在分组中移除服务器，不存在时返回 false
+func (b *HierarchicalBalancer) RemoveServer(addr string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, g := range b.groups {
+		for i, s := range g.servers {
+			if s == addr {
+				g.servers = append(g.servers[:i:i], g.servers[i+1:]...)
+				b.recompute()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GroupWeights 当前各分组的有效权重
+func (b *HierarchicalBalancer) GroupWeights() map[string]int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	weights := make(map[string]int, len(b.effective))
+	for _, s := range b.effective {
+		weights[s.Addr] = s.Weight
+	}
+	return weights
+}
+
+func (b *HierarchicalBalancer) Next() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	name := weightedPick(b.rng, &b.fallback, b.effective, nil)
+	for _, g := range b.groups {
+		if g.name != name {
+			continue
+		}
+		// 分组内轮询，跳过不健康的服务器
+		for i := 0; i < len(g.servers); i++ {
+			addr := g.servers[g.index%uint64(len(g.servers))]
+			g.index++
+			if !b.unhealthy[addr] {
+				return addr
+			}
+		}
+	}
+	return ""
+}
+
+// recompute 重新计算分组有效权重，调用方需要持有锁
+func (b *HierarchicalBalancer) recompute() {
+	b.effective = b.effective[:0]
+	for _, g := range b.groups {
+		healthy := 0
+		for _, addr := range g.servers {
+			if !b.unhealthy[addr] {
+				healthy++
+			}
+		}
+		weight := healthy
+		if b.mode == GroupWeightStatic && healthy > 0 {
+			weight = g.weight
+		}
+		b.effective = append(b.effective, &Server{Addr: g.name, Weight: weight})
+	}
+}
@@ -0,0 +1,87 @@
+package balance
+
+import (
+	"strings"
+	"testing"
+)
+
+func groupShare(b *HierarchicalBalancer, prefix string, iterations int) float64 {
+	hits := 0
+	for i := 0; i < iterations; i++ {
+		if strings.HasPrefix(b.Next(), prefix) {
+			hits++
+		}
+	}
+	return float64(hits) / float64(iterations)
+}
+
+func TestHierarchicalBalancer_HealthyCountWeights(t *testing.T) {
+	balancer, err := NewHierarchicalBalancer([]Group{
+		{Name: "a", Servers: []string{"a1", "a2", "a3", "a4"}},
+		{Name: "b", Servers: []string{"b1", "b2", "b3", "b4"}},
+	}, GroupWeightHealthyCount)
+	if err != nil {
+		t.Fatalf("NewHierarchicalBalancer() error = %v", err)
+	}
+
+	if share := groupShare(balancer, "a", 20000); share < 0.47 || share > 0.53 {
+		t.Errorf("group a share = %.3f, want ~0.5", share)
+	}
+
+	// 移除 a 的一半服务器，a 的权重减半：4:4 -> 2:4
+	balancer.RemoveServer("a1")
+	balancer.RemoveServer("a2")
+	if w := balancer.GroupWeights(); w["a"] != 2 || w["b"] != 4 {
+		t.Errorf("GroupWeights() = %v, want a=2 b=4", w)
+	}
+	if share := groupShare(balancer, "a", 20000); share < 0.30 || share > 0.37 {
+		t.Errorf("group a share = %.3f, want ~0.33", share)
+	}
+
+	// 健康状态变化同样生效
+	balancer.SetHealthy("b1", false)
+	balancer.SetHealthy("b2", false)
+	if w := balancer.GroupWeights(); w["b"] != 2 {
+		t.Errorf("GroupWeights() = %v, want b=2", w)
+	}
+	balancer.AddServer("a", "a5")
+	if w := balancer.GroupWeights(); w["a"] != 3 {
+		t.Errorf("GroupWeights() = %v, want a=3", w)
+	}
+}
+
+func TestHierarchicalBalancer_StaticWeights(t *testing.T) {
+	balancer, err := NewHierarchicalBalancer([]Group{
+		{Name: "a", Weight: 3, Servers: []string{"a1"}},
+		{Name: "b", Weight: 1, Servers: []string{"b1", "b2"}},
+	}, GroupWeightStatic)
+	if err != nil {
+		t.Fatalf("NewHierarchicalBalancer() error = %v", err)
+	}
+
+	if share := groupShare(balancer, "a", 20000); share < 0.72 || share > 0.78 {
+		t.Errorf("group a share = %.3f, want ~0.75", share)
+	}
+
+	// 分组内全部不健康时，流量全部流向其它分组
+	balancer.SetHealthy("a1", false)
+	if share := groupShare(balancer, "a", 1000); share != 0 {
+		t.Errorf("group a share = %.3f, want 0", share)
+	}
+}
+
+func TestHierarchicalBalancer_InvalidWeights(t *testing.T) {
+	for _, groups := range [][]Group{
+		{{Name: "a", Weight: -1, Servers: []string{"a1"}}},
+		{{Name: "a", Weight: maxWeight + 1, Servers: []string{"a1"}}},
+	} {
+		if _, err := NewHierarchicalBalancer(groups, GroupWeightStatic); err == nil {
+			t.Errorf("NewHierarchicalBalancer(%+v) error = nil, want error", groups)
+		}
+	}
+
+	// GroupWeightHealthyCount 不使用配置的权重
+	if _, err := NewHierarchicalBalancer([]Group{{Name: "a", Weight: -1, Servers: []string{"a1"}}}, GroupWeightHealthyCount); err != nil {
+		t.Errorf("NewHierarchicalBalancer(healthy count) error = %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package balance
+
+// defaultHistorySize 未指定 WithHistory 时保留的选择数量
+const defaultHistorySize = 100
+
+// HistoryBalancer
+// 记住最近 size 次 Next 选出的地址，排查"这个请求为什么去了那里"时使用
+// 写入不加锁，内存占用固定，见 recentRing；内部返回空地址时不记录，不会挤掉真实的选择
+type HistoryBalancer struct {
+	balancer Balancer
+	ring     *recentRing[string]
+}
+
+// NewHistoryBalancer 保留的数量由 WithHistory 指定，默认为 defaultHistorySize
+func NewHistoryBalancer(b Balancer, opts ...Option) *HistoryBalancer {
+	o := newOptions(opts)
+	size := o.history
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &HistoryBalancer{
+		balancer: b,
+		ring:     newRecentRing[string](size),
+	}
+}
+
+func (h *HistoryBalancer) Next() string {
+	addr := h.balancer.Next()
+	if addr != "" {
+		h.ring.add(addr)
+	}
+	return addr
+}
+
+// History 按时间顺序（从旧到新）返回最近选出的地址，最多 size 个
+// 与写入并发时，可能缺少正在写入的那一条
+func (h *HistoryBalancer) History() []string {
+	return h.ring.snapshot()
+}
@@ -0,0 +1,63 @@
+package balance
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestHistoryBalancer_RetainsLastK(t *testing.T) {
+	balancer := NewHistoryBalancer(newOrderedRoundRobin([]string{"a", "b", "c"}), WithHistory(5))
+	if got := balancer.History(); len(got) != 0 {
+		t.Errorf("History() = %v, want empty", got)
+	}
+
+	for i := 0; i < 7; i++ {
+		balancer.Next()
+	}
+	// 7 次选择 a b c a b c a，只保留最近 5 次
+	want := []string{"c", "a", "b", "c", "a"}
+	if got := balancer.History(); !slices.Equal(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryBalancer_SkipsEmpty(t *testing.T) {
+	calls := 0
+	balancer := NewHistoryBalancer(balancerFunc(func() string {
+		calls++
+		if calls > 2 {
+			return ""
+		}
+		return []string{"a", "b"}[calls-1]
+	}), WithHistory(2))
+
+	for i := 0; i < 5; i++ {
+		balancer.Next()
+	}
+	if got, want := balancer.History(), []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryBalancer_Concurrent(t *testing.T) {
+	const size = 16
+	balancer := NewHistoryBalancer(NewRoundRobinBalancer([]string{"a", "b"}), WithHistory(size))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				balancer.Next()
+				balancer.History()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := balancer.History(); len(got) != size {
+		t.Errorf("len(History()) = %d, want %d", len(got), size)
+	}
+}
@@ -0,0 +1,36 @@
+package balance
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+// IPHashBalancer
+// 按客户端 IP 哈希取模，同一个 IP 总是落到同一台服务器，适合简单的会话保持
+// 服务器数量变化时大部分映射都会改变，需要平滑迁移时使用 ConsistentHashBalancer
+type IPHashBalancer struct {
+	servers []string
+	index   uint64 // ip 为 nil 时轮询使用
+}
+
+func NewIPHashBalancer(servers []string) *IPHashBalancer {
+	return &IPHashBalancer{
+		servers: append([]string(nil), servers...),
+	}
+}
+
+// NextForIP 支持 IPv4 和 IPv6，IPv4 的 4 字节和 16 字节形式映射到同一台服务器
+// ip 为 nil 时退化为轮询；没有服务器时返回空字符串
+func (b *IPHashBalancer) NextForIP(ip net.IP) string {
+	n := uint64(len(b.servers))
+	if n == 0 {
+		return ""
+	}
+	if ip == nil {
+		return b.servers[(atomic.AddUint64(&b.index, 1)-1)%n]
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	return b.servers[hashKey(string(ip))%n]
+}
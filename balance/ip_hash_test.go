@@ -0,0 +1,70 @@
+package balance
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func TestIPHashBalancer_SameIPSameServer(t *testing.T) {
+	balancer := NewIPHashBalancer([]string{"s1", "s2", "s3"})
+
+	ips := []net.IP{
+		net.ParseIP("192.168.1.10"),
+		net.ParseIP("10.0.0.1"),
+		net.ParseIP("2001:db8::1"),
+		net.ParseIP("fe80::abcd"),
+	}
+	for _, ip := range ips {
+		first := balancer.NextForIP(ip)
+		for i := 0; i < 10; i++ {
+			if got := balancer.NextForIP(ip); got != first {
+				t.Errorf("NextForIP(%v) = %v, want %v", ip, got, first)
+			}
+		}
+	}
+
+	// 4 字节和 16 字节形式的同一个 IPv4 地址结果相同
+	v4 := net.IPv4(172, 16, 0, 5)
+	if balancer.NextForIP(v4) != balancer.NextForIP(v4.To4()) {
+		t.Error("IPv4 in 4-byte and 16-byte form should map to the same server")
+	}
+}
+
+func TestIPHashBalancer_Distribution(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := NewIPHashBalancer(servers)
+	rng := rand.New(rand.NewSource(1))
+
+	counts := make(map[string]int)
+	const n = 20000
+	for i := 0; i < n; i++ {
+		var ip net.IP
+		if i%2 == 0 {
+			ip = net.IPv4(byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256)))
+		} else {
+			ip = make(net.IP, net.IPv6len)
+			rng.Read(ip)
+		}
+		counts[balancer.NextForIP(ip)]++
+	}
+	for _, s := range servers {
+		if share := float64(counts[s]) / n; share < 0.22 || share > 0.28 {
+			t.Errorf("%s share = %.3f, want ~0.25", s, share)
+		}
+	}
+}
+
+func TestIPHashBalancer_NilIPRoundRobin(t *testing.T) {
+	balancer := NewIPHashBalancer([]string{"s1", "s2"})
+	want := []string{"s1", "s2", "s1"}
+	for _, w := range want {
+		if got := balancer.NextForIP(nil); got != w {
+			t.Errorf("NextForIP(nil) = %v, want %v", got, w)
+		}
+	}
+
+	if got := NewIPHashBalancer(nil).NextForIP(net.ParseIP("10.0.0.1")); got != "" {
+		t.Errorf("NextForIP() on empty pool = %v, want empty", got)
+	}
+}
@@ -0,0 +1,67 @@
+package balance
+
+import (
+	"sync"
+)
+
+// LeastConnectionsBalancer
+// 最少连接：每次选择在途请求最少的服务器，相同时选下标最小的
+// Next 选中后在途请求数加一，请求结束后需要调用 Done
+type LeastConnectionsBalancer struct {
+	servers []string
+	active  []int
+	lock    sync.Mutex
+}
+
+func NewLeastConnectionsBalancer(servers []string) *LeastConnectionsBalancer {
+	return &LeastConnectionsBalancer{
+		servers: append([]string(nil), servers...),
+		active:  make([]int, len(servers)),
+	}
+}
+
+func (b *LeastConnectionsBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *LeastConnectionsBalancer) NextE() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	best := 0
+	for i := 1; i < len(b.active); i++ {
+		if b.active[i] < b.active[best] {
+			best = i
+		}
+	}
+	b.active[best]++
+	return b.servers[best], nil
+}
+
+// Done 请求结束，addr 的在途请求数减一，不会减到负数；未知地址会被忽略
+func (b *LeastConnectionsBalancer) Done(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, s := range b.servers {
+		if s == addr && b.active[i] > 0 {
+			b.active[i]--
+			return
+		}
+	}
+}
+
+// Active 返回 addr 当前的在途请求数
+func (b *LeastConnectionsBalancer) Active(addr string) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, s := range b.servers {
+		if s == addr {
+			return b.active[i]
+		}
+	}
+	return 0
+}
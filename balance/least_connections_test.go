@@ -0,0 +1,102 @@
+package balance
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLeastConnectionsBalancer_PicksLeastActive(t *testing.T) {
+	balancer := NewLeastConnectionsBalancer([]string{"s1", "s2", "s3"})
+
+	// 全部为0时按下标顺序
+	want := []string{"s1", "s2", "s3", "s1"}
+	for _, w := range want {
+		if got := balancer.Next(); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+
+	// s1=2 s2=1 s3=1，释放 s3 后它最少
+	balancer.Done("s3")
+	if got := balancer.Next(); got != "s3" {
+		t.Errorf("Next() = %v, want s3", got)
+	}
+
+	// 多余的 Done 不会减到负数
+	for i := 0; i < 5; i++ {
+		balancer.Done("s2")
+	}
+	if got := balancer.Active("s2"); got != 0 {
+		t.Errorf("Active(s2) = %d, want 0", got)
+	}
+}
+
+func TestLeastConnectionsBalancer_Empty(t *testing.T) {
+	balancer := NewLeastConnectionsBalancer(nil)
+	if _, err := balancer.NextE(); err != ErrEmptyPool {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+}
+
+func TestLeastConnectionsBalancer_Concurrency(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := NewLeastConnectionsBalancer(servers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				addr := balancer.Next()
+				if n := balancer.Active(addr); n < 0 {
+					t.Errorf("Active(%s) = %d, want >= 0", addr, n)
+				}
+				balancer.Done(addr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, s := range servers {
+		if n := balancer.Active(s); n != 0 {
+			t.Errorf("Active(%s) = %d after all Done, want 0", s, n)
+		}
+	}
+
+	// 持有 100 个请求不释放，在途数应当完全均衡
+	for i := 0; i < 100; i++ {
+		balancer.Next()
+	}
+	for _, s := range servers {
+		if n := balancer.Active(s); n != 25 {
+			t.Errorf("Active(%s) = %d, want 25", s, n)
+		}
+	}
+}
+
+func TestLeastConnectionsBalancer_FewServers(t *testing.T) {
+	// 单台服务器始终返回它本身，不会因为找不到第二个候选而卡住
+	single := NewLeastConnectionsBalancer([]string{"only"})
+	for i := 0; i < 100; i++ {
+		if got := single.Next(); got != "only" {
+			t.Fatalf("Next() = %v, want only", got)
+		}
+	}
+	if got := single.Active("only"); got != 100 {
+		t.Errorf("Active(only) = %d, want 100", got)
+	}
+
+	// 两台服务器交替选择，释放后回到在途请求较少的一台
+	pair := NewLeastConnectionsBalancer([]string{"s1", "s2"})
+	want := []string{"s1", "s2", "s1", "s2"}
+	for _, w := range want {
+		if got := pair.Next(); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+	pair.Done("s2")
+	if got := pair.Next(); got != "s2" {
+		t.Errorf("Next() = %v, want s2", got)
+	}
+}
@@ -0,0 +1,114 @@
+package balance
+
+import (
+	"sync"
+	"time"
+)
+
+// responseTimeSamples 每个服务器保留的最近样本数，超过后覆盖最旧的样本
+const responseTimeSamples = 64
+
+type responseSample struct {
+	d  time.Duration
+	at time.Time
+}
+
+// responseRing 固定容量的环形缓冲区
+type responseRing struct {
+	samples [responseTimeSamples]responseSample
+	next    int
+	n       int
+}
+
+func (r *responseRing) add(s responseSample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.n < len(r.samples) {
+		r.n++
+	}
+}
+
+// average 返回 since 之后样本的平均值，没有样本时 ok 为 false
+func (r *responseRing) average(since time.Time) (avg time.Duration, ok bool) {
+	var sum time.Duration
+	var n int
+	for i := 0; i < r.n; i++ {
+		if s := r.samples[i]; s.at.After(since) {
+			sum += s.d
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / time.Duration(n), true
+}
+
+// LeastResponseTimeBalancer
+// 按滑动时间窗口内的平均响应时间选择最快的服务器，相同时选下标最小的
+// 和 PeakEWMABalancer 不同，窗口外的样本直接失效，而不是按时间衰减
+// 窗口内没有样本的服务器视为最快，保证新节点和长时间没有流量的慢节点会被重新探测
+type LeastResponseTimeBalancer struct {
+	servers []string
+	samples map[string]*responseRing
+	window  time.Duration
+	clock   Clock
+	lock    sync.Mutex
+}
+
+// NewLeastResponseTimeBalancer window 为统计窗口，小于等于0时按 10 秒处理
+func NewLeastResponseTimeBalancer(servers []string, window time.Duration, opts ...Option) *LeastResponseTimeBalancer {
+	o := newOptions(opts)
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+	b := &LeastResponseTimeBalancer{
+		servers: append([]string(nil), servers...),
+		samples: make(map[string]*responseRing, len(servers)),
+		window:  window,
+		clock:   o.clock,
+	}
+	for _, addr := range b.servers {
+		b.samples[addr] = &responseRing{}
+	}
+	return b
+}
+
+func (b *LeastResponseTimeBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *LeastResponseTimeBalancer) NextE() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	since := b.clock.Now().Add(-b.window)
+	var (
+		best    string
+		bestAvg time.Duration
+	)
+	for _, addr := range b.servers {
+		avg, ok := b.samples[addr].average(since)
+		if !ok {
+			// 没有样本，直接探测
+			return addr, nil
+		}
+		if best == "" || avg < bestAvg {
+			best, bestAvg = addr, avg
+		}
+	}
+	return best, nil
+}
+
+// Observe 上报一次请求的响应时间，未知地址会被忽略
+func (b *LeastResponseTimeBalancer) Observe(addr string, d time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if ring, ok := b.samples[addr]; ok {
+		ring.add(responseSample{d: d, at: b.clock.Now()})
+	}
+}
@@ -0,0 +1,76 @@
+package balance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeastResponseTimeBalancer_PicksFastest(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	balancer := NewLeastResponseTimeBalancer([]string{"a", "b", "c"}, time.Minute, WithClock(clock))
+
+	// 没有样本的服务器按顺序先被探测
+	for _, want := range []string{"a", "b", "c"} {
+		got := balancer.Next()
+		if got != want {
+			t.Fatalf("Next() = %v, want %v", got, want)
+		}
+		balancer.Observe(got, map[string]time.Duration{"a": 30, "b": 10, "c": 20}[got]*time.Millisecond)
+	}
+	if got := balancer.Next(); got != "b" {
+		t.Errorf("Next() = %v, want fastest b", got)
+	}
+
+	// 平均值而不是最近一次样本决定排序
+	balancer.Observe("b", 60*time.Millisecond)
+	if got := balancer.Next(); got != "c" {
+		t.Errorf("Next() = %v, want c (avg 20ms) over b (avg 35ms)", got)
+	}
+
+	balancer.Observe("unknown", time.Millisecond)
+	if _, err := NewLeastResponseTimeBalancer(nil, time.Minute).NextE(); err != ErrEmptyPool {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+}
+
+func TestLeastResponseTimeBalancer_ProbesStarvedServer(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	balancer := NewLeastResponseTimeBalancer([]string{"slow", "fast"}, time.Second, WithClock(clock))
+
+	latency := map[string]time.Duration{
+		"slow": 100 * time.Millisecond,
+		"fast": 10 * time.Millisecond,
+	}
+
+	// slow 只在开始时有样本，之后流量全部流向 fast，直到它的样本滑出窗口
+	slowHits := 0
+	for i := 0; i < 300; i++ {
+		addr := balancer.Next()
+		if addr == "slow" {
+			slowHits++
+			if i > 0 && i < 100 {
+				t.Fatalf("call %d: slow selected while its samples are still in the window", i)
+			}
+		}
+		balancer.Observe(addr, latency[addr])
+		clock.Advance(10 * time.Millisecond)
+	}
+	if slowHits < 2 {
+		t.Errorf("slow selected %d times, want it re-probed after its samples expire", slowHits)
+	}
+}
+
+func TestResponseRing_Wraps(t *testing.T) {
+	var ring responseRing
+	at := time.Unix(1, 0)
+	for i := 0; i < responseTimeSamples; i++ {
+		ring.add(responseSample{d: time.Second, at: at})
+	}
+	// 覆盖所有旧样本后平均值只反映最近的样本
+	for i := 0; i < responseTimeSamples; i++ {
+		ring.add(responseSample{d: time.Millisecond, at: at})
+	}
+	if avg, ok := ring.average(time.Unix(0, 0)); !ok || avg != time.Millisecond {
+		t.Errorf("average() = %v, %v, want 1ms, true", avg, ok)
+	}
+}
@@ -0,0 +1,65 @@
+package balance
+
+// maglevTableSize 查找表大小，必须是质数，且远大于服务器数量（论文建议至少 100 倍）
+const maglevTableSize = 65537
+
+// MaglevBalancer
+// Maglev 一致性哈希（Google, NSDI 2016）：构造时按每个服务器的排列偏好填充固定大小的查找表，
+// 查询只需要一次哈希和一次数组访问，O(1)；各服务器在表中的份额几乎完全相等，
+// 移除一个服务器时只有略多于 1/N 的表项发生变化
+type MaglevBalancer struct {
+	servers []string
+	table   []int32 // 表项为 servers 的下标
+}
+
+func NewMaglevBalancer(servers []string) *MaglevBalancer {
+	b := &MaglevBalancer{
+		servers: append([]string(nil), servers...),
+	}
+	if len(b.servers) > 0 {
+		b.table = maglevPopulate(b.servers, maglevTableSize)
+	}
+	return b
+}
+
+// NextForKey 没有服务器时返回空字符串
+func (b *MaglevBalancer) NextForKey(key string) string {
+	if len(b.table) == 0 {
+		return ""
+	}
+	return b.servers[b.table[hashKey(key)%uint64(len(b.table))]]
+}
+
+// maglevPopulate 每个服务器按 offset、skip 生成对表项的偏好排列，
+// 轮流让每个服务器占用自己偏好中下一个空闲的表项，直到表被填满
+func maglevPopulate(servers []string, m int) []int32 {
+	n := len(servers)
+	offset := make([]uint64, n)
+	skip := make([]uint64, n)
+	next := make([]uint64, n)
+	for i, s := range servers {
+		offset[i] = hashKey("maglev-offset#"+s) % uint64(m)
+		skip[i] = hashKey("maglev-skip#"+s)%uint64(m-1) + 1
+	}
+
+	table := make([]int32, m)
+	for i := range table {
+		table[i] = -1
+	}
+	filled := 0
+	for {
+		for i := 0; i < n; i++ {
+			c := (offset[i] + next[i]*skip[i]) % uint64(m)
+			for table[c] >= 0 {
+				next[i]++
+				c = (offset[i] + next[i]*skip[i]) % uint64(m)
+			}
+			table[c] = int32(i)
+			next[i]++
+			filled++
+			if filled == m {
+				return table
+			}
+		}
+	}
+}
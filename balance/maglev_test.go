@@ -0,0 +1,62 @@
+package balance
+
+import (
+	"strconv"
+	"testing"
+)
+
+func maglevServers(n int) []string {
+	servers := make([]string, n)
+	for i := range servers {
+		servers[i] = "backend-" + strconv.Itoa(i)
+	}
+	return servers
+}
+
+func TestMaglevBalancer_EvenTable(t *testing.T) {
+	servers := maglevServers(8)
+	balancer := NewMaglevBalancer(servers)
+
+	counts := make(map[int32]int)
+	for _, idx := range balancer.table {
+		counts[idx]++
+	}
+	ideal := float64(maglevTableSize) / float64(len(servers))
+	for i := range servers {
+		if dev := float64(counts[int32(i)])/ideal - 1; dev < -0.02 || dev > 0.02 {
+			t.Errorf("%s owns %d entries, want ~%.0f", servers[i], counts[int32(i)], ideal)
+		}
+	}
+}
+
+func TestMaglevBalancer_RemoveDisruption(t *testing.T) {
+	servers := maglevServers(8)
+	before := NewMaglevBalancer(servers)
+	after := NewMaglevBalancer(append(servers[:3:3], servers[4:]...))
+
+	changed := 0
+	for i := 0; i < maglevTableSize; i++ {
+		if before.servers[before.table[i]] != after.servers[after.table[i]] {
+			changed++
+		}
+	}
+	// 理论最小值是被移除服务器原有的 1/8 表项
+	disruption := float64(changed) / maglevTableSize
+	t.Logf("disruption = %.4f, theoretical minimum = %.4f", disruption, 1.0/8)
+	if disruption < 1.0/8-0.01 || disruption > 1.0/8*1.5 {
+		t.Errorf("disruption = %.4f, want close to %.4f", disruption, 1.0/8)
+	}
+}
+
+func TestMaglevBalancer_NextForKey(t *testing.T) {
+	balancer := NewMaglevBalancer(maglevServers(4))
+	for i := 0; i < 100; i++ {
+		key := "key-" + strconv.Itoa(i)
+		if got := balancer.NextForKey(key); got == "" || got != balancer.NextForKey(key) {
+			t.Fatalf("NextForKey(%s) = %q, not stable", key, got)
+		}
+	}
+	if got := NewMaglevBalancer(nil).NextForKey("k"); got != "" {
+		t.Errorf("NextForKey() on empty = %q, want empty", got)
+	}
+}
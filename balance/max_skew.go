@@ -0,0 +1,64 @@
+package balance
+
+import (
+	"sync"
+)
+
+// skewWindowPerServer 滑动窗口的大小为可用服务器数量乘以该值
+const skewWindowPerServer = 50
+
+// skewGuard 维护最近的选择记录，在选择即将打破倾斜上限时改选最饥饿的服务器
+type skewGuard struct {
+	ratio  float64
+	window []string
+	pos    int
+	counts map[string]int
+	lock   sync.Mutex
+}
+
+func newSkewGuard(ratio float64) *skewGuard {
+	return &skewGuard{
+		ratio:  ratio,
+		counts: make(map[string]int),
+	}
+}
+
+// adjust 返回最终选择的服务器，只考虑权重为正的服务器
+func (g *skewGuard) adjust(addr string, servers []*Server) string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	var (
+		starved  string
+		minCount int
+		n        int
+	)
+	for _, s := range servers {
+		if s.Weight <= 0 {
+			continue
+		}
+		n++
+		if c := g.counts[s.Addr]; starved == "" || c < minCount {
+			starved, minCount = s.Addr, c
+		}
+	}
+
+	// 选中后 addr 的次数会加一，超过最少者的 ratio 倍时改选最饥饿的服务器
+	if starved != "" && float64(g.counts[addr]+1) > g.ratio*float64(max(minCount, 1)) {
+		addr = starved
+	}
+	g.record(addr, n*skewWindowPerServer)
+	return addr
+}
+
+func (g *skewGuard) record(addr string, size int) {
+	if len(g.window) < size {
+		g.window = append(g.window, addr)
+	} else {
+		old := g.window[g.pos]
+		g.counts[old]--
+		g.window[g.pos] = addr
+		g.pos = (g.pos + 1) % len(g.window)
+	}
+	g.counts[addr]++
+}
@@ -0,0 +1,60 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestWithMaxSkew_BoundsSkew(t *testing.T) {
+	const ratio = 3.0
+	// 对抗性的权重：不加限制时 a 的选中次数是 b、c 的上千倍
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "a", Weight: 10000},
+		{Addr: "b", Weight: 1},
+		{Addr: "c", Weight: 1},
+	}, WithMaxSkew(ratio))
+
+	window := 3 * skewWindowPerServer
+	seq := make([]string, 5000)
+	for i := range seq {
+		seq[i] = balancer.Next()
+	}
+
+	// 检查每个完整的滑动窗口
+	counts := make(map[string]int)
+	for i, addr := range seq {
+		counts[addr]++
+		if i >= window {
+			counts[seq[i-window]]--
+		}
+		if i < window-1 {
+			continue
+		}
+		maxCount, minCount := 0, window
+		for _, s := range []string{"a", "b", "c"} {
+			maxCount = max(maxCount, counts[s])
+			minCount = min(minCount, counts[s])
+		}
+		// 窗口滑动时旧记录移出可能让最少者先少一次，允许一次的误差
+		if float64(maxCount) > ratio*float64(minCount)+ratio {
+			t.Fatalf("window ending at %d: skew %d/%d exceeds ratio %.1f", i, maxCount, minCount, ratio)
+		}
+	}
+	t.Logf("last window counts: %v", counts)
+}
+
+func TestWithMaxSkew_RespectsWeightsWithinBound(t *testing.T) {
+	// 权重比 2:1 在 3 倍限制之内，分布不受影响
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "a", Weight: 2},
+		{Addr: "b", Weight: 1},
+	}, WithMaxSkew(3))
+
+	results := make(map[string]int)
+	for i := 0; i < 30000; i++ {
+		results[balancer.Next()]++
+	}
+	ratio := float64(results["a"]) / float64(results["b"])
+	if ratio < 1.8 || ratio > 2.2 {
+		t.Errorf("Expected a/b ratio around 2.0, got %.2f", ratio)
+	}
+}
@@ -0,0 +1,18 @@
+package balance
+
+import "time"
+
+// MetricsSink 选择和请求结果的上报接口，由调用方实现并对接自己的监控系统（Prometheus、OTel 等）
+// 负载均衡器只依赖这个接口，不关心具体的监控实现
+type MetricsSink interface {
+	// RecordSelection 每次选中一个服务器时调用
+	RecordSelection(addr string)
+	// RecordOutcome 请求完成时调用，rtt 为耗时，err 为请求错误
+	RecordOutcome(addr string, rtt time.Duration, err error)
+}
+
+// TimingSink 接收 Next 的耗时样本（包括等锁的时间），通常记录为直方图，用来判断负载均衡器本身是否成为瓶颈
+// MetricsSink 的实现可以同时实现这个接口
+type TimingSink interface {
+	RecordNextDuration(d time.Duration)
+}
@@ -0,0 +1,48 @@
+package balance_test
+
+import (
+	"context"
+	"testing"
+
+	"interview/balance"
+)
+
+func TestNewNode_ExternalPackage(t *testing.T) {
+	nodes := []*balance.Node{
+		balance.NewNode("a", 3),
+		balance.NewNode("b", 1),
+	}
+	if nodes[0].Server() != "a" || nodes[0].Weight() != 3 {
+		t.Fatalf("NewNode() = %v/%d, want a/3", nodes[0].Server(), nodes[0].Weight())
+	}
+
+	balancer := balance.NewSmoothRRBalancer(nodes)
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		counts[balancer.Next(context.Background()).Server()]++
+	}
+	if counts["a"] != 6 || counts["b"] != 2 {
+		t.Errorf("distribution = %v, want a=6 b=2", counts)
+	}
+
+	if _, err := balance.NewSmoothRRBalancerE([]*balance.Node{balance.NewNode("c", 0)}); err == nil {
+		t.Error("NewSmoothRRBalancerE() with zero weight should fail")
+	}
+}
+
+func TestWithLess_ExternalPackage(t *testing.T) {
+	// 当前权重相等时地址大的优先，比较函数只能拿到快照
+	byAddrDesc := balance.WithLess(func(a, b balance.NodeSnapshot) bool {
+		if a.Current != b.Current {
+			return a.Current > b.Current
+		}
+		return a.Server > b.Server
+	})
+	balancer := balance.NewSmoothRRBalancer([]*balance.Node{
+		balance.NewNode("a", 1),
+		balance.NewNode("b", 1),
+	}, byAddrDesc)
+	if got := balancer.Next(context.Background()).Server(); got != "b" {
+		t.Errorf("Next() = %v, want b", got)
+	}
+}
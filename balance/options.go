@@ -0,0 +1,132 @@
+package balance
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Option 负载均衡器的通用可选配置
+type Option func(*options)
+
+type options struct {
+	clock        Clock
+	override     DecisionOverride
+	probes       []string
+	probeEvery   int
+	maxSkew      float64
+	source       rand.Source
+	fallback     Balancer
+	virtualNodes int
+	onSelect     func(addr string)
+	slowStart    time.Duration
+	history      int
+}
+
+// DecisionOverride 混沌测试用的决策覆盖钩子
+// candidates 是算法本次会考虑的候选服务器（过滤之后）；返回 ok=true 时用返回值替代算法的选择，
+// 返回值可以不在候选集中，用来模拟错误路由；返回 ok=false 时按正常算法选择
+type DecisionOverride func(candidates []string) (string, bool)
+
+// WithClock 指定时间来源，测试时可以传入 FakeClock
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithDecisionOverride 设置决策覆盖钩子
+func WithDecisionOverride(fn DecisionOverride) Option {
+	return func(o *options) {
+		o.override = fn
+	}
+}
+
+// WithGuaranteedProbe 保证 servers 中的每个服务器在任意连续 everyN 次选择中至少被选中一次，
+// 不管它们的权重有多小，适合给低权重的金丝雀持续导入真实流量
+// 探测位置在每 everyN 次选择中均匀分布，其余选择按正常权重进行；everyN 小于服务器数量时按服务器数量计算
+func WithGuaranteedProbe(servers []string, everyN int) Option {
+	return func(o *options) {
+		o.probes = append([]string(nil), servers...)
+		o.probeEvery = max(everyN, len(servers))
+	}
+}
+
+// WithMaxSkew 保证最近的滑动窗口内，任何服务器被选中的次数不超过最少被选中的可用服务器的 ratio 倍，
+// 即将违反时强制选择最饥饿的服务器。ratio 小于 1 时按 1 处理
+func WithMaxSkew(ratio float64) Option {
+	return func(o *options) {
+		o.maxSkew = max(ratio, 1)
+	}
+}
+
+// WithRandSource 指定随机数来源，默认使用以当前时间为种子的源
+func WithRandSource(src rand.Source) Option {
+	return func(o *options) {
+		o.source = src
+	}
+}
+
+// WithRNGFallback 指定随机源故障（Int63 panic）时使用的负载均衡器
+// 不指定时，在同一个（按权重展开的）服务器集合上做确定性轮询
+func WithRNGFallback(b Balancer) Option {
+	return func(o *options) {
+		o.fallback = b
+	}
+}
+
+// WithVirtualNodes 一致性哈希中每个服务器的虚拟节点数，越多分布越均匀，但占用更多内存
+func WithVirtualNodes(n int) Option {
+	return func(o *options) {
+		o.virtualNodes = n
+	}
+}
+
+// WithOnSelect 每次选出非空地址后调用 fn，适合上报选择次数等指标
+// fn 在负载均衡器释放所有锁之后调用，可以安全地回调负载均衡器
+func WithOnSelect(fn func(addr string)) Option {
+	return func(o *options) {
+		o.onSelect = fn
+	}
+}
+
+// WithSlowStart 新加入的服务器在 window 内逐步预热：有效权重从接近0线性增长到配置的权重，避免刚启动就被打满
+// 只对之后通过 SetServers 新加入的地址生效，构造时传入的服务器视为已经预热；时间来源见 WithClock
+func WithSlowStart(window time.Duration) Option {
+	return func(o *options) {
+		o.slowStart = window
+	}
+}
+
+// WithHistory NewHistoryBalancer 保留最近 size 次选择
+func WithHistory(size int) Option {
+	return func(o *options) {
+		o.history = size
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.clock = clockOrSystem(o.clock)
+	if o.source == nil {
+		o.source = rand.NewSource(time.Now().UnixNano())
+	}
+	return o
+}
+
+// applyOverride 钩子为空时不会构造候选集，没有额外开销
+func applyOverride(fn DecisionOverride, candidates func() []string) (string, bool) {
+	if fn == nil {
+		return "", false
+	}
+	return fn(candidates())
+}
+
+// notifySelect 选择成功后调用 WithOnSelect 的回调
+func notifySelect(fn func(addr string), addr string, err error) {
+	if fn != nil && err == nil && addr != "" {
+		fn(addr)
+	}
+}
@@ -0,0 +1,109 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestDecisionOverride_TakesEffect(t *testing.T) {
+	var seen []string
+	pin := WithDecisionOverride(func(candidates []string) (string, bool) {
+		seen = candidates
+		return "pinned", true
+	})
+
+	balancers := map[string]Balancer{
+		"round_robin":   NewRoundRobinBalancer([]string{"s1", "s2"}, pin),
+		"random":        NewRandomBalancer([]string{"s1", "s2"}, pin),
+		"random_weight": NewRandomWeightBalancer([]*Server{{Addr: "s1", Weight: 1}, {Addr: "s2", Weight: 1}}, pin),
+	}
+	for name, b := range balancers {
+		seen = nil
+		if got := b.Next(); got != "pinned" {
+			t.Errorf("%s: Next() = %v, want pinned", name, got)
+		}
+		if len(seen) != 2 || seen[0] != "s1" || seen[1] != "s2" {
+			t.Errorf("%s: candidates = %v, want [s1 s2]", name, seen)
+		}
+	}
+}
+
+func TestDecisionOverride_SeesFilteredCandidates(t *testing.T) {
+	var seen []string
+	servers := []string{"s1", "s2", "s3"}
+	balancer := NewHealthBalancer(NewRoundRobinBalancer(servers), servers, WithDecisionOverride(func(candidates []string) (string, bool) {
+		seen = candidates
+		return "", false
+	}))
+	balancer.SetHealthy("s2", false)
+
+	balancer.Next()
+	if len(seen) != 2 || seen[0] != "s1" || seen[1] != "s3" {
+		t.Errorf("candidates = %v, want [s1 s3]", seen)
+	}
+
+	weighted := NewRandomWeightBalancer([]*Server{{Addr: "s1", Weight: 0}, {Addr: "s2", Weight: 1}}, WithDecisionOverride(func(candidates []string) (string, bool) {
+		seen = candidates
+		return "", false
+	}))
+	weighted.Next()
+	if len(seen) != 1 || seen[0] != "s2" {
+		t.Errorf("candidates = %v, want [s2]", seen)
+	}
+}
+
+func TestDecisionOverride_Declines(t *testing.T) {
+	calls := 0
+	balancer := newOrderedRoundRobin([]string{"s1", "s2", "s3"}, WithDecisionOverride(func(candidates []string) (string, bool) {
+		calls++
+		return "", false
+	}))
+
+	want := []string{"s1", "s2", "s3", "s1"}
+	for _, w := range want {
+		if got := balancer.Next(); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+	if calls != len(want) {
+		t.Errorf("override called %d times, want %d", calls, len(want))
+	}
+}
+
+func TestWithOnSelect(t *testing.T) {
+	var (
+		balancer Balancer
+		count    int
+		seen     []string
+	)
+	onSelect := func(addr string) {
+		count++
+		seen = append(seen, addr)
+		// 回调在锁外执行，重入负载均衡器不会死锁
+		_ = balancer.(ProbabilityReporter).Probabilities()
+	}
+
+	balancers := map[string]Balancer{
+		"round robin":   NewRoundRobinBalancer([]string{"s1", "s2"}, WithOnSelect(onSelect)),
+		"random":        NewRandomBalancer([]string{"s1", "s2"}, WithOnSelect(onSelect)),
+		"random weight": NewRandomWeightBalancer([]*Server{{Addr: "s1", Weight: 1}, {Addr: "s2", Weight: 2}}, WithOnSelect(onSelect)),
+	}
+	for name, b := range balancers {
+		balancer, count, seen = b, 0, nil
+		for i := 0; i < 10; i++ {
+			if addr := b.Next(); addr != seen[len(seen)-1] {
+				t.Errorf("%s: callback got %v, Next() returned %v", name, seen[len(seen)-1], addr)
+			}
+		}
+		if count != 10 {
+			t.Errorf("%s: callback fired %d times, want 10", name, count)
+		}
+	}
+
+	// 选择失败时不调用
+	count = 0
+	empty := NewRandomWeightBalancer([]*Server{{Addr: "s1", Weight: 0}}, WithOnSelect(onSelect))
+	empty.Next()
+	if count != 0 {
+		t.Errorf("callback fired %d times on failed selection, want 0", count)
+	}
+}
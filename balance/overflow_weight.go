@@ -0,0 +1,72 @@
+package balance
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// OverflowWeightBalancer
+// 加权随机 + 最小连接的混合：服务器的在途请求达到上限时，有效权重降为0
+// 它的份额按权重比例溢出到其它服务器，释放后恢复
+type OverflowWeightBalancer struct {
+	servers  []*Server
+	caps     map[string]int
+	inflight map[string]int
+	rng      *rand.Rand
+	fallback rngFallback
+	lock     sync.Mutex
+}
+
+// NewOverflowWeightBalancer caps 为每个服务器的在途请求上限，不在 caps 中的服务器不限制
+// 权重按与 NewRandomWeightBalancerE 相同的规则校验（maxWeight、maxTotalWeight）
+func NewOverflowWeightBalancer(servers []*Server, caps map[string]int) (*OverflowWeightBalancer, error) {
+	if err := validateWeights(servers, maxWeight, maxTotalWeight); err != nil {
+		return nil, err
+	}
+	b := &OverflowWeightBalancer{
+		servers:  append([]*Server(nil), servers...),
+		caps:     make(map[string]int, len(caps)),
+		inflight: make(map[string]int, len(servers)),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for addr, c := range caps {
+		b.caps[addr] = c
+	}
+	return b, nil
+}
+
+// Next 选中的服务器在途请求数加一，请求结束后需要调用 Done
+func (b *OverflowWeightBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *OverflowWeightBalancer) NextE() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	addr := weightedPick(b.rng, &b.fallback, b.servers, b.unsaturated)
+	if addr == "" {
+		return "", ErrAllUnavailable
+	}
+	b.inflight[addr]++
+	return addr, nil
+}
+
+// Done 请求结束，释放 addr 的一个在途名额
+func (b *OverflowWeightBalancer) Done(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.inflight[addr] > 0 {
+		b.inflight[addr]--
+	}
+}
+
+func (b *OverflowWeightBalancer) unsaturated(s *Server) bool {
+	c, ok := b.caps[s.Addr]
+	return !ok || b.inflight[s.Addr] < c
+}
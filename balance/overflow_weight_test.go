@@ -0,0 +1,79 @@
+package balance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOverflowWeightBalancer_Redistributes(t *testing.T) {
+	balancer, err := NewOverflowWeightBalancer([]*Server{
+		{Addr: "a", Weight: 2},
+		{Addr: "b", Weight: 1},
+		{Addr: "c", Weight: 3},
+	}, map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("NewOverflowWeightBalancer() error = %v", err)
+	}
+
+	// a 只有一个名额，选中一次后就饱和
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		addr := balancer.Next()
+		results[addr]++
+		if addr != "a" {
+			balancer.Done(addr)
+		}
+	}
+
+	if results["a"] != 1 {
+		t.Errorf("saturated server selected %d times, want 1", results["a"])
+	}
+	ratio := float64(results["c"]) / float64(results["b"])
+	t.Logf("Saturated distribution: %v, c/b ratio %.2f", results, ratio)
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected c/b ratio around 3.0, got %.2f", ratio)
+	}
+
+	// 释放后恢复份额
+	balancer.Done("a")
+	results = make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		addr := balancer.Next()
+		results[addr]++
+		balancer.Done(addr)
+	}
+	share := float64(results["a"]) / 10000
+	t.Logf("Restored distribution: %v", results)
+	if share < 0.30 || share > 0.37 {
+		t.Errorf("Expected a share around 0.33 after release, got %.3f", share)
+	}
+}
+
+func TestOverflowWeightBalancer_AllSaturated(t *testing.T) {
+	balancer, err := NewOverflowWeightBalancer([]*Server{{Addr: "a", Weight: 1}}, map[string]int{"a": 2})
+	if err != nil {
+		t.Fatalf("NewOverflowWeightBalancer() error = %v", err)
+	}
+	balancer.Next()
+	balancer.Next()
+
+	if _, err := balancer.NextE(); !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+
+	balancer.Done("a")
+	if got := balancer.Next(); got != "a" {
+		t.Errorf("Next() = %v, want a after release", got)
+	}
+}
+
+func TestOverflowWeightBalancer_InvalidWeights(t *testing.T) {
+	for _, servers := range [][]*Server{
+		{{Addr: "a", Weight: -1}},
+		{{Addr: "a", Weight: maxWeight + 1}},
+	} {
+		if _, err := NewOverflowWeightBalancer(servers, nil); err == nil {
+			t.Errorf("NewOverflowWeightBalancer(%v) should fail", servers[0])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package balance
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// peakEWMAState 单个服务器的延迟估计
+type peakEWMAState struct {
+	ewma float64 // 纳秒
+	last time.Time
+}
+
+// PeakEWMABalancer
+// 基于时间衰减的延迟 EWMA：样本的权重按 halfLife 随时间衰减，而不是按样本个数
+// 延迟突增时直接取峰值，立刻避开变慢的服务器；之后按半衰期慢慢回落
+// 打分时按距离上次观测的时间继续衰减，长时间没有流量的慢节点会重新被探测到
+// 没有样本的服务器分数为0，保证新节点会被探测到
+type PeakEWMABalancer struct {
+	servers  []string
+	state    map[string]*peakEWMAState
+	halfLife time.Duration
+	clock    Clock
+	lock     sync.Mutex
+}
+
+// NewPeakEWMABalancer halfLife 为延迟估计的半衰期，小于等于0时按 10 秒处理
+func NewPeakEWMABalancer(servers []string, halfLife time.Duration, opts ...Option) *PeakEWMABalancer {
+	o := newOptions(opts)
+	if halfLife <= 0 {
+		halfLife = 10 * time.Second
+	}
+	return &PeakEWMABalancer{
+		servers:  append([]string(nil), servers...),
+		state:    make(map[string]*peakEWMAState, len(servers)),
+		halfLife: halfLife,
+		clock:    o.clock,
+	}
+}
+
+func (p *PeakEWMABalancer) Next() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Now()
+	var (
+		best      string
+		bestScore float64
+	)
+	for _, addr := range p.servers {
+		var score float64
+		if st, ok := p.state[addr]; ok {
+			score = st.ewma * p.decay(now.Sub(st.last))
+		}
+		if best == "" || score < bestScore {
+			best, bestScore = addr, score
+		}
+	}
+	return best
+}
+
+// Observe 上报一次请求的延迟，未知地址会被忽略
+func (p *PeakEWMABalancer) Observe(addr string, latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock.Now()
+	sample := float64(latency)
+	st, ok := p.state[addr]
+	if !ok {
+		for _, s := range p.servers {
+			if s == addr {
+				p.state[addr] = &peakEWMAState{ewma: sample, last: now}
+				return
+			}
+		}
+		return
+	}
+
+	if sample > st.ewma {
+		st.ewma = sample
+	} else {
+		w := p.decay(now.Sub(st.last))
+		st.ewma = st.ewma*w + sample*(1-w)
+	}
+	st.last = now
+}
+
+// decay 经过 elapsed 之后旧值保留的比例
+func (p *PeakEWMABalancer) decay(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(elapsed) / float64(p.halfLife))
+}
@@ -0,0 +1,62 @@
+package balance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeakEWMABalancer_ShiftsAwayFromSlowServer(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	balancer := NewPeakEWMABalancer([]string{"slow", "fast1", "fast2"}, time.Second, WithClock(clock))
+
+	latency := map[string]time.Duration{
+		"slow":  100 * time.Millisecond,
+		"fast1": 10 * time.Millisecond,
+		"fast2": 10 * time.Millisecond,
+	}
+
+	const rounds = 2000
+	slowHits := 0
+	for i := 0; i < rounds; i++ {
+		addr := balancer.Next()
+		if i >= rounds/2 && addr == "slow" {
+			slowHits++
+		}
+		balancer.Observe(addr, latency[addr])
+		clock.Advance(10 * time.Millisecond)
+	}
+
+	// 后半段 slow 只会在分数衰减后偶尔被重新探测
+	if share := float64(slowHits) / (rounds / 2); share > 0.05 {
+		t.Errorf("slow server share = %.3f, want traffic shifted away (< 0.05)", share)
+	}
+	if slowHits == 0 {
+		t.Error("slow server should still be probed occasionally as its score decays")
+	}
+}
+
+func TestPeakEWMABalancer_PeakSensitive(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	balancer := NewPeakEWMABalancer([]string{"a", "b"}, time.Minute, WithClock(clock))
+
+	balancer.Observe("a", 10*time.Millisecond)
+	balancer.Observe("b", 20*time.Millisecond)
+	if got := balancer.Next(); got != "a" {
+		t.Fatalf("Next() = %v, want a", got)
+	}
+
+	// 一次延迟突增立刻生效
+	balancer.Observe("a", 500*time.Millisecond)
+	if got := balancer.Next(); got != "b" {
+		t.Errorf("Next() = %v, want b after a's latency spike", got)
+	}
+}
+
+func TestPeakEWMABalancer_UnobservedFirst(t *testing.T) {
+	balancer := NewPeakEWMABalancer([]string{"a", "b"}, time.Second)
+	balancer.Observe("a", time.Millisecond)
+	balancer.Observe("unknown", time.Millisecond)
+	if got := balancer.Next(); got != "b" {
+		t.Errorf("Next() = %v, want unobserved b", got)
+	}
+}
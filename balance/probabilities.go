@@ -0,0 +1,90 @@
+package balance
+
+import "slices"
+
+// ProbabilityReporter 能给出每个服务器理论选中概率的负载均衡器
+// 用于上线前校验配置，不需要真的调用上百万次 Next 去统计
+type ProbabilityReporter interface {
+	Probabilities() map[string]float64
+}
+
+// uniformProbabilities 每个位置等概率，重复出现的地址概率累加
+func uniformProbabilities(servers []string) map[string]float64 {
+	probs := make(map[string]float64, len(servers))
+	for _, addr := range servers {
+		probs[addr] += 1 / float64(len(servers))
+	}
+	return probs
+}
+
+// Probabilities 轮询为就绪服务器之间的 1/N，未就绪的服务器（见 NewRoundRobinBalancerFunc）概率为0
+func (r *RoundRobinBalancer) Probabilities() map[string]float64 {
+	servers := r.rr.load()
+	probs := uniformProbabilities(slices.DeleteFunc(slices.Clone(servers), func(addr string) bool {
+		return !r.isReady(addr)
+	}))
+	for _, addr := range servers {
+		if _, ok := probs[addr]; !ok {
+			probs[addr] = 0
+		}
+	}
+	return probs
+}
+
+// Probabilities 随机为 1/N
+func (r *RandomBalancer) Probabilities() map[string]float64 {
+	return uniformProbabilities(r.rand.items)
+}
+
+// Probabilities 加权随机为 weight/total，权重不为正或被标记为不健康的服务器概率为0
+// 不包含 DecisionOverride、WithGuaranteedProbe 和 WithSlowStart 带来的影响
+func (r *RandomWeightBalancer) Probabilities() map[string]float64 {
+	servers := r.servers.Load().servers
+	probs := make(map[string]float64, len(servers))
+	var total int64
+	for _, s := range servers {
+		// 权重为0的服务器也出现在结果中，概率为0
+		probs[s.Addr] = 0
+		if s.Weight > 0 && r.isHealthy(s.Addr) {
+			total += int64(s.Weight)
+		}
+	}
+	if total == 0 {
+		return probs
+	}
+	for _, s := range servers {
+		if s.Weight > 0 && r.isHealthy(s.Addr) {
+			probs[s.Addr] += float64(s.Weight) / float64(total)
+		}
+	}
+	return probs
+}
+
+// Probabilities 在内部负载均衡器的概率基础上去掉不可用的服务器并重新归一化
+// 内部负载均衡器没有实现 ProbabilityReporter 时，按可用服务器等概率计算
+func (h *HealthBalancer) Probabilities() map[string]float64 {
+	var base map[string]float64
+	if r, ok := h.balancer.(ProbabilityReporter); ok {
+		base = r.Probabilities()
+	} else {
+		base = uniformProbabilities(h.servers)
+	}
+
+	probs := make(map[string]float64, len(base))
+	total := 0.0
+	for addr, p := range base {
+		if h.IsAvailable(addr) {
+			probs[addr] = p
+			total += p
+		} else {
+			probs[addr] = 0
+		}
+	}
+	if total == 0 {
+		return probs
+	}
+	for addr := range probs {
+		probs[addr] /= total
+	}
+	return probs
+}
@@ -0,0 +1,64 @@
+package balance
+
+import (
+	"math"
+	"testing"
+)
+
+// assertMatchesEmpirical 统计实际分布，并与 Probabilities 的理论值比较
+func assertMatchesEmpirical(t *testing.T, name string, b Balancer, iterations int, tolerance float64) {
+	t.Helper()
+	probs := b.(ProbabilityReporter).Probabilities()
+
+	counts := make(map[string]int)
+	for i := 0; i < iterations; i++ {
+		counts[b.Next()]++
+	}
+
+	sum := 0.0
+	for addr, p := range probs {
+		sum += p
+		actual := float64(counts[addr]) / float64(iterations)
+		if math.Abs(actual-p) > tolerance {
+			t.Errorf("%s: %s expected %.3f, observed %.3f", name, addr, p, actual)
+		}
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("%s: probabilities sum to %.6f, want 1", name, sum)
+	}
+}
+
+func TestProbabilities_MatchEmpirical(t *testing.T) {
+	weighted := NewRandomWeightBalancer([]*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 3},
+		{Addr: "c", Weight: 0},
+	})
+	probs := weighted.(ProbabilityReporter).Probabilities()
+	if probs["a"] != 0.25 || probs["b"] != 0.75 || probs["c"] != 0 {
+		t.Errorf("weighted probabilities = %v", probs)
+	}
+
+	servers := []string{"s1", "s2", "s3", "s4"}
+	healthRR := NewHealthBalancer(NewRoundRobinBalancer(servers), servers)
+	healthRR.SetHealthy("s2", false)
+
+	weightedServers := []*Server{{Addr: "a", Weight: 1}, {Addr: "b", Weight: 2}, {Addr: "c", Weight: 1}}
+	healthWeighted := NewHealthBalancer(NewRandomWeightBalancer(weightedServers), []string{"a", "b", "c"})
+	healthWeighted.Drain("c")
+
+	balancers := map[string]Balancer{
+		"round_robin":     NewRoundRobinBalancer([]string{"x", "y", "z"}),
+		"random":          NewRandomBalancer([]string{"x", "y", "z"}),
+		"random_weight":   weighted,
+		"health_rr":       healthRR,
+		"health_weighted": healthWeighted,
+	}
+	for name, b := range balancers {
+		assertMatchesEmpirical(t, name, b, 60000, 0.02)
+	}
+
+	if p := healthRR.Probabilities(); math.Abs(p["s1"]-1.0/3) > 1e-9 || p["s2"] != 0 {
+		t.Errorf("health_rr probabilities = %v", p)
+	}
+}
@@ -0,0 +1,36 @@
+package balance
+
+// QuorumBalancer
+// 可用服务器少于 minHealthy 时拒绝服务（防脑裂），恢复后自动继续
+// 内部的负载均衡器需要能报告可用数量（比如 HealthBalancer），在构造时由参数类型保证
+type QuorumBalancer struct {
+	balancer   ReportingBalancer
+	minHealthy int
+}
+
+func NewQuorumBalancer(b ReportingBalancer, minHealthy int) *QuorumBalancer {
+	return &QuorumBalancer{
+		balancer:   b,
+		minHealthy: minHealthy,
+	}
+}
+
+func (q *QuorumBalancer) Next() string {
+	addr, _ := q.NextE()
+	return addr
+}
+
+// NextE 法定数量不足时返回 ErrInsufficientQuorum
+func (q *QuorumBalancer) NextE() (string, error) {
+	if q.balancer.Available() < q.minHealthy {
+		return "", ErrInsufficientQuorum
+	}
+	if eb, ok := q.balancer.(ErrorBalancer); ok {
+		return eb.NextE()
+	}
+	addr := q.balancer.Next()
+	if addr == "" {
+		return "", ErrAllUnavailable
+	}
+	return addr, nil
+}
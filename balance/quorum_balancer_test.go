@@ -0,0 +1,54 @@
+package balance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuorumBalancer_HaltsAndResumes(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	health := NewHealthBalancer(NewRoundRobinBalancer(servers), servers)
+	balancer := NewQuorumBalancer(health, 2)
+
+	if _, err := balancer.NextE(); err != nil {
+		t.Fatalf("NextE() error = %v with full quorum", err)
+	}
+
+	// 一台不健康，仍满足法定数量
+	health.SetHealthy("s1", false)
+	addr, err := balancer.NextE()
+	if err != nil || addr == "s1" {
+		t.Errorf("NextE() = %v, %v, want healthy server", addr, err)
+	}
+
+	// 再摘除一台，低于法定数量
+	health.Drain("s2")
+	if _, err := balancer.NextE(); !errors.Is(err, ErrInsufficientQuorum) {
+		t.Errorf("NextE() error = %v, want ErrInsufficientQuorum", err)
+	}
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty below quorum", got)
+	}
+
+	// 恢复后继续服务
+	health.SetHealthy("s1", true)
+	if _, err := balancer.NextE(); err != nil {
+		t.Errorf("NextE() error = %v after recovery", err)
+	}
+}
+
+// fixedReporter 报告固定可用数量的负载均衡器
+type fixedReporter struct {
+	Balancer
+	available int
+}
+
+func (f fixedReporter) Available() int { return f.available }
+
+func TestQuorumBalancer_FailsClosed(t *testing.T) {
+	// 可用数量为0时拒绝服务，即使内部的负载均衡器仍能返回地址
+	balancer := NewQuorumBalancer(fixedReporter{Balancer: NewRoundRobinBalancer([]string{"s1"})}, 1)
+	if _, err := balancer.NextE(); !errors.Is(err, ErrInsufficientQuorum) {
+		t.Errorf("NextE() error = %v, want ErrInsufficientQuorum", err)
+	}
+}
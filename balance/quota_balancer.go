@@ -0,0 +1,85 @@
+package balance
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// QuotaBalancer
+// 按后端上报的剩余限流配额做加权随机：剩余配额越多，分到的流量越多
+// 配额为0的服务器会被跳过，直到它重新上报可用配额
+type QuotaBalancer struct {
+	addrs    []string
+	quota    map[string]int
+	rng      *rand.Rand
+	fallback rngFallback
+	lock     sync.Mutex
+}
+
+// NewQuotaBalancer 使用 Server.Weight 作为初始配额，收到第一次上报前按它分配流量
+// 支持 WithRandSource
+func NewQuotaBalancer(servers []*Server, opts ...Option) *QuotaBalancer {
+	o := newOptions(opts)
+	q := &QuotaBalancer{
+		addrs: make([]string, 0, len(servers)),
+		quota: make(map[string]int, len(servers)),
+		rng:   rand.New(o.source),
+	}
+	for _, s := range servers {
+		if _, ok := q.quota[s.Addr]; !ok {
+			q.addrs = append(q.addrs, s.Addr)
+		}
+		q.quota[s.Addr] = max(s.Weight, 0)
+	}
+	return q
+}
+
+// ReportQuota 更新 addr 的剩余配额，未知地址会被忽略
+func (q *QuotaBalancer) ReportQuota(addr string, remaining int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if _, ok := q.quota[addr]; !ok {
+		return
+	}
+	q.quota[addr] = max(remaining, 0)
+}
+
+func (q *QuotaBalancer) Next() string {
+	addr, _ := q.NextE()
+	return addr
+}
+
+func (q *QuotaBalancer) NextE() (string, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.addrs) == 0 {
+		return "", ErrEmptyPool
+	}
+
+	// 配额由后端上报，没有上限；总和溢出时截断为 math.MaxInt64，排在后面的服务器只会少分到一点流量
+	var total int64
+	for _, addr := range q.addrs {
+		var ok bool
+		if total, ok = addWeight(total, int64(q.quota[addr])); !ok {
+			total = math.MaxInt64
+			break
+		}
+	}
+	if total <= 0 {
+		return "", ErrAllUnavailable
+	}
+
+	idx, ok := tryInt63n(q.rng, total)
+	if !ok {
+		idx = q.fallback.next(total)
+	}
+	for _, addr := range q.addrs {
+		idx -= int64(q.quota[addr])
+		if idx < 0 {
+			return addr, nil
+		}
+	}
+	return "", ErrAllUnavailable
+}
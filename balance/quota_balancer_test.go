@@ -0,0 +1,93 @@
+package balance
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestQuotaBalancer_ProportionalToQuota(t *testing.T) {
+	balancer := NewQuotaBalancer([]*Server{
+		{Addr: "server1", Weight: 1},
+		{Addr: "server2", Weight: 1},
+	})
+	balancer.ReportQuota("server1", 300)
+	balancer.ReportQuota("server2", 100)
+
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.Next()]++
+	}
+
+	ratio := float64(results["server1"]) / float64(results["server2"])
+	t.Logf("Distribution: %v, ratio %.2f", results, ratio)
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected server1/server2 ratio around 3.0, got %.2f", ratio)
+	}
+}
+
+func TestQuotaBalancer_ZeroQuotaSkipped(t *testing.T) {
+	balancer := NewQuotaBalancer([]*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 10},
+	})
+	balancer.ReportQuota("server1", 0)
+
+	for i := 0; i < 1000; i++ {
+		if got := balancer.Next(); got != "server2" {
+			t.Fatalf("Next() = %v, want server2", got)
+		}
+	}
+
+	// 重新上报配额后恢复
+	balancer.ReportQuota("server1", 10)
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		seen[balancer.Next()] = true
+	}
+	if !seen["server1"] {
+		t.Errorf("server1 should rejoin after reporting quota")
+	}
+}
+
+func TestQuotaBalancer_AllExhausted(t *testing.T) {
+	balancer := NewQuotaBalancer([]*Server{{Addr: "server1", Weight: 10}})
+	balancer.ReportQuota("server1", 0)
+	balancer.ReportQuota("unknown", 100)
+
+	if _, err := balancer.NextE(); !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+	if _, err := NewQuotaBalancer(nil).NextE(); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+}
+
+func TestQuotaBalancer_OverflowAndRandSource(t *testing.T) {
+	sequence := func() []string {
+		balancer := NewQuotaBalancer([]*Server{
+			{Addr: "server1", Weight: 1},
+			{Addr: "server2", Weight: 1},
+		}, WithRandSource(rand.NewSource(1)))
+		// 配额总和超过 int64 时截断，不会 panic
+		balancer.ReportQuota("server1", math.MaxInt)
+		balancer.ReportQuota("server2", math.MaxInt)
+
+		var got []string
+		for i := 0; i < 20; i++ {
+			addr, err := balancer.NextE()
+			if err != nil {
+				t.Fatalf("NextE() error = %v", err)
+			}
+			got = append(got, addr)
+		}
+		return got
+	}
+
+	// 相同的随机源得到相同的选择序列
+	if a, b := sequence(), sequence(); !slices.Equal(a, b) {
+		t.Errorf("sequences differ with the same source: %v vs %v", a, b)
+	}
+}
@@ -0,0 +1,60 @@
+package balance
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RampingCanaryBalancer
+// 金丝雀发布：canary 的流量占比在 ramp 时间内从0线性增长到 targetPct，之后保持不变
+type RampingCanaryBalancer struct {
+	stable    Balancer
+	canary    Balancer
+	targetPct int
+	ramp      time.Duration
+	start     time.Time
+	clock     Clock
+	rng       *rand.Rand
+	mu        sync.Mutex
+}
+
+// NewRampingCanaryBalancer 从构造时开始计时，targetPct 会被限制在 [0, 100]
+// 支持 WithClock 和 WithRandSource
+func NewRampingCanaryBalancer(stable, canary Balancer, targetPct int, ramp time.Duration, opts ...Option) *RampingCanaryBalancer {
+	o := newOptions(opts)
+	return &RampingCanaryBalancer{
+		stable:    stable,
+		canary:    canary,
+		targetPct: min(max(targetPct, 0), 100),
+		ramp:      ramp,
+		start:     o.clock.Now(),
+		clock:     o.clock,
+		rng:       rand.New(o.source),
+	}
+}
+
+// CanaryPercent 当前 canary 的流量占比（0~100）
+func (c *RampingCanaryBalancer) CanaryPercent() float64 {
+	elapsed := c.clock.Now().Sub(c.start)
+	if c.ramp <= 0 || elapsed >= c.ramp {
+		return float64(c.targetPct)
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(c.targetPct) * float64(elapsed) / float64(c.ramp)
+}
+
+func (c *RampingCanaryBalancer) Next() string {
+	pct := c.CanaryPercent()
+
+	c.mu.Lock()
+	toCanary := c.rng.Float64()*100 < pct
+	c.mu.Unlock()
+
+	if toCanary {
+		return c.canary.Next()
+	}
+	return c.stable.Next()
+}
@@ -0,0 +1,66 @@
+package balance
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func canaryShare(b *RampingCanaryBalancer, iterations int) float64 {
+	hits := 0
+	for i := 0; i < iterations; i++ {
+		if b.Next() == "canary" {
+			hits++
+		}
+	}
+	return float64(hits) / float64(iterations)
+}
+
+func TestRampingCanaryBalancer_Ramp(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewRampingCanaryBalancer(
+		NewRoundRobinBalancer([]string{"stable"}),
+		NewRoundRobinBalancer([]string{"canary"}),
+		20, 10*time.Minute, WithClock(clock),
+	)
+
+	if share := canaryShare(balancer, 10000); share > 0.005 {
+		t.Errorf("t=0: canary share = %.3f, want ~0", share)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if pct := balancer.CanaryPercent(); pct != 10 {
+		t.Errorf("t=ramp/2: CanaryPercent() = %.2f, want 10", pct)
+	}
+	if share := canaryShare(balancer, 20000); share < 0.08 || share > 0.12 {
+		t.Errorf("t=ramp/2: canary share = %.3f, want ~0.10", share)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if share := canaryShare(balancer, 20000); share < 0.18 || share > 0.22 {
+		t.Errorf("t=ramp: canary share = %.3f, want ~0.20", share)
+	}
+
+	// 之后保持在目标值
+	clock.Advance(time.Hour)
+	if pct := balancer.CanaryPercent(); pct != 20 {
+		t.Errorf("after ramp: CanaryPercent() = %.2f, want 20", pct)
+	}
+}
+
+func TestRampingCanaryBalancer_RandSource(t *testing.T) {
+	// 相同的随机数源得到相同的分流结果
+	newBalancer := func() *RampingCanaryBalancer {
+		return NewRampingCanaryBalancer(
+			NewRoundRobinBalancer([]string{"stable"}),
+			NewRoundRobinBalancer([]string{"canary"}),
+			50, 0, WithRandSource(rand.NewSource(42)),
+		)
+	}
+	b1, b2 := newBalancer(), newBalancer()
+	for i := 0; i < 100; i++ {
+		if got1, got2 := b1.Next(), b2.Next(); got1 != got2 {
+			t.Fatalf("call %d: %v != %v", i, got1, got2)
+		}
+	}
+}
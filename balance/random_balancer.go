@@ -2,29 +2,116 @@ package balance
 
 import (
 	"math/rand"
-	"sync"
-	"time"
+	"slices"
 )
 
 type RandomBalancer struct {
-	servers []string
-	rng     *rand.Rand
-	mu      sync.Mutex
+	rand     *Random[string]
+	override DecisionOverride
+	onSelect func(addr string)
+	clock    Clock
+	fallback Balancer // 随机源故障时使用，为空时按确定性轮询
 }
 
-func NewRandomBalancer(servers []string) Balancer {
+// NewRandomBalancer 复制一份 servers，之后调用方修改原切片不会影响负载均衡器
+func NewRandomBalancer(servers []string, opts ...Option) Balancer {
+	o := newOptions(opts)
 	return &RandomBalancer{
-		servers: servers,
-		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand:     newRandom(slices.Clone(servers), o.source),
+		override: o.override,
+		onSelect: o.onSelect,
+		clock:    o.clock,
+		fallback: o.fallback,
 	}
 }
 
+// NewRandomBalancerE 与 NewRandomBalancer 相同，但 servers 为空时返回 ErrEmptyPool
+func NewRandomBalancerE(servers []string, opts ...Option) (Balancer, error) {
+	if len(servers) == 0 {
+		return nil, ErrEmptyPool
+	}
+	return NewRandomBalancer(servers, opts...), nil
+}
+
+// NewRandomBalancerWithSeed 使用固定种子，相同种子的两个实例产生相同的选择序列，方便测试复现
+func NewRandomBalancerWithSeed(servers []string, seed int64, opts ...Option) Balancer {
+	return NewRandomBalancer(servers, slices.Concat(opts, []Option{WithRandSource(rand.NewSource(seed))})...)
+}
+
 func (r *RandomBalancer) Next() string {
-	if len(r.servers) == 0 {
+	addr, _ := r.NextE()
+	return addr
+}
+
+func (r *RandomBalancer) NextE() (string, error) {
+	addr, err := r.next()
+	notifySelect(r.onSelect, addr, err)
+	return addr, err
+}
+
+// NextIndex 同时返回选中服务器在 Servers() 中的下标，没有服务器时返回 (-1, "")
+func (r *RandomBalancer) NextIndex() (int, string) {
+	i, addr, err := r.nextIndex()
+	notifySelect(r.onSelect, addr, err)
+	return i, addr
+}
+
+func (r *RandomBalancer) next() (string, error) {
+	_, addr, err := r.nextIndex()
+	return addr, err
+}
+
+func (r *RandomBalancer) nextIndex() (int, string, error) {
+	if r.rand.Len() == 0 {
+		return -1, "", ErrEmptyPool
+	}
+	if addr, ok := applyOverride(r.override, r.Servers); ok {
+		return slices.Index(r.rand.items, addr), addr, nil
+	}
+	i, addr, rngOK := r.rand.pick()
+	if !rngOK && r.fallback != nil {
+		addr = r.fallback.Next()
+		return slices.Index(r.rand.items, addr), addr, nil
+	}
+	return i, addr, nil
+}
+
+// NextN 不放回地随机选出 n 个不同的服务器，适合对冲请求同时发往多个后端
+// 服务器不足 n 个时返回全部服务器（随机顺序），每个恰好一次；n 小于等于0或没有服务器时返回 nil
+// DecisionOverride 和 WithRNGFallback 不作用于 NextN，随机源故障时按确定性轮询抽取
+func (r *RandomBalancer) NextN(n int) []string {
+	n = min(n, r.rand.Len())
+	if n <= 0 {
+		return nil
+	}
+	addrs := r.rand.sample(n)
+	for _, addr := range addrs {
+		notifySelect(r.onSelect, addr, nil)
+	}
+	return addrs
+}
+
+// NextExcluding 在 exclude 之外的服务器中均匀随机选择，全部被排除时返回空字符串
+// DecisionOverride 和 WithRNGFallback 不作用于 NextExcluding
+func (r *RandomBalancer) NextExcluding(exclude ...string) string {
+	candidates := slices.DeleteFunc(r.rand.Items(), func(addr string) bool {
+		return slices.Contains(exclude, addr)
+	})
+	if len(candidates) == 0 {
 		return ""
 	}
-	r.mu.Lock()
-	idx := r.rng.Intn(len(r.servers))
-	r.mu.Unlock()
-	return r.servers[idx]
+	i, _ := r.rand.intn(int64(len(candidates)))
+	addr := candidates[i]
+	notifySelect(r.onSelect, addr, nil)
+	return addr
+}
+
+// Servers 返回服务器列表的副本
+func (r *RandomBalancer) Servers() []string {
+	return r.rand.Items()
+}
+
+// Len 返回服务器数量
+func (r *RandomBalancer) Len() int {
+	return r.rand.Len()
 }
@@ -135,14 +135,16 @@ func TestRandomBalancer_ModifyOriginalSlice(t *testing.T) {
 	servers[0] = "modified"
 	servers = append(servers, "server4")
 
-	// RandomBalancer 应该仍然使用原始的切片引用
-	// 这是预期的行为，虽然可能导致问题
-	for i := 0; i < 10; i++ {
-		got := balancer.Next()
-		if got == "server4" {
-			// 这说明 balancer 使用的是原始切片的引用
-			t.Logf("Balancer uses original slice reference (got 'server4')")
-		}
+	// RandomBalancer 持有自己的副本，不受调用方修改的影响
+	seen := make(map[string]bool)
+	for i := 0; i < 300; i++ {
+		seen[balancer.Next()] = true
+	}
+	if seen["modified"] || seen["server4"] {
+		t.Errorf("balancer affected by caller mutation: saw %v", seen)
+	}
+	if !seen["server1"] {
+		t.Errorf("server1 should still be selected, saw %v", seen)
 	}
 }
 
@@ -168,3 +170,106 @@ func BenchmarkRandomBalancer_Next_Serial(b *testing.B) {
 		balancer.Next()
 	}
 }
+
+func TestRandomBalancer_WithSeed(t *testing.T) {
+	servers := []string{"server1", "server2", "server3", "server4"}
+	b1 := NewRandomBalancerWithSeed(servers, 42)
+	b2 := NewRandomBalancerWithSeed(servers, 42)
+
+	for i := 0; i < 100; i++ {
+		got1, got2 := b1.Next(), b2.Next()
+		if got1 != got2 {
+			t.Fatalf("call %d: %v != %v, same seed should give the same sequence", i, got1, got2)
+		}
+	}
+}
+
+func TestRandomBalancer_NextIndex(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := NewRandomBalancer(servers).(*RandomBalancer)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				idx, addr := balancer.NextIndex()
+				if idx < 0 || idx >= len(servers) || servers[idx] != addr {
+					t.Errorf("NextIndex() = %d, %v out of bounds or mismatched", idx, addr)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	empty := NewRandomBalancer(nil).(*RandomBalancer)
+	if idx, addr := empty.NextIndex(); idx != -1 || addr != "" {
+		t.Errorf("NextIndex() on empty = %d, %q, want -1, \"\"", idx, addr)
+	}
+}
+
+func TestRandomBalancer_NextN(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4", "s5"}
+	balancer := NewRandomBalancerWithSeed(servers, 1).(*RandomBalancer)
+
+	seen := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		got := balancer.NextN(3)
+		if len(got) != 3 {
+			t.Fatalf("NextN(3) = %v, want 3 servers", got)
+		}
+		if got[0] == got[1] || got[0] == got[2] || got[1] == got[2] {
+			t.Fatalf("NextN(3) = %v, want distinct servers", got)
+		}
+		for _, addr := range got {
+			seen[addr]++
+		}
+	}
+	// 不放回抽样下每个服务器被选中的概率都是 3/5
+	for _, addr := range servers {
+		if seen[addr] < 500 || seen[addr] > 700 {
+			t.Errorf("server %s picked %d times, want around 600 (%v)", addr, seen[addr], seen)
+		}
+	}
+
+	// n 超过服务器数量时每个服务器恰好返回一次
+	all := balancer.NextN(10)
+	if len(all) != len(servers) {
+		t.Fatalf("NextN(10) = %v, want all %d servers", all, len(servers))
+	}
+	counts := make(map[string]int)
+	for _, addr := range all {
+		counts[addr]++
+	}
+	for _, addr := range servers {
+		if counts[addr] != 1 {
+			t.Errorf("NextN(10) = %v, want each server exactly once", all)
+			break
+		}
+	}
+
+	if got := NewRandomBalancer(nil).(*RandomBalancer).NextN(2); got != nil {
+		t.Errorf("NextN() on empty = %v, want nil", got)
+	}
+}
+
+func TestRandomBalancer_NextExcluding(t *testing.T) {
+	balancer := NewRandomBalancerWithSeed([]string{"s1", "s2", "s3"}, 1).(*RandomBalancer)
+
+	results := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		results[balancer.NextExcluding("s2", "unknown")]++
+	}
+	if results["s2"] != 0 || results[""] != 0 {
+		t.Fatalf("NextExcluding(s2) = %v, want only s1 and s3", results)
+	}
+	if ratio := float64(results["s1"]) / float64(results["s3"]); ratio < 0.85 || ratio > 1.15 {
+		t.Errorf("Expected s1/s3 ratio around 1.0, got %.2f (%v)", ratio, results)
+	}
+
+	if got := balancer.NextExcluding("s1", "s2", "s3"); got != "" {
+		t.Errorf("NextExcluding(all) = %v, want empty string", got)
+	}
+}
@@ -1,56 +1,450 @@
 package balance
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"math/rand"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 type Server struct {
-	Addr   string
+	Addr string
+	// Weight is the relative share of traffic. A zero weight marks the server
+	// as disabled but present: it is never selected, yet still listed by
+	// Servers and reported with probability 0.
 	Weight int
+	// Types lists the request types this server is capable of handling,
+	// see RandomWeightBalancer.NextForType
+	Types []string
+	// Tags are free-form attributes such as version or capability, see
+	// RandomWeightBalancer.NextMatching
+	Tags map[string]string
 }
 
+// RandomWeightBalancer keeps everything selection reads about the servers in
+// one immutable weightedSnapshot. Readers load it once per call, so they
+// always see servers, total weight and warm-up state from the same version;
+// writers (SetServers, ScaleWeights) build a new snapshot and swap it whole.
+// The lock only guards rng, and health marks are a separate copy-on-write map.
 type RandomWeightBalancer struct {
-	servers atomic.Value
-	rng     *rand.Rand
-	lock    sync.RWMutex
+	servers  atomic.Pointer[weightedSnapshot]
+	rng      *rand.Rand
+	lock     sync.RWMutex
+	override DecisionOverride
+	onSelect func(addr string)
+	fallback rngFallback
+
+	// Guaranteed probe schedule, see WithGuaranteedProbe
+	probeAt map[uint64]string
+	every   uint64
+	calls   atomic.Uint64
+
+	// Optional skew invariant, see WithMaxSkew
+	skew *skewGuard
+
+	// Addresses marked unhealthy via SetHealthy. Copy-on-write like servers,
+	// so Next never takes a lock to read it; healthLock serializes writers.
+	unhealthy  atomic.Pointer[map[string]bool]
+	healthLock sync.Mutex
+
+	// Serializes SetServers and ScaleWeights, which derive the new snapshot
+	// from the current one
+	writeLock sync.Mutex
+
+	// Limits SetServers validates against
+	maxWeight int64
+	maxTotal  int64
+
+	// Slow-start window for servers added by SetServers, see WithSlowStart
+	slowStart time.Duration
+	clock     Clock
 }
 
-func NewRandomWeightBalancer(servers []*Server) Balancer {
+// NewRandomWeightBalancer deep-copies servers, so later changes by the caller
+// are not seen; use SetServers to change them.
+func NewRandomWeightBalancer(servers []*Server, opts ...Option) Balancer {
+	o := newOptions(opts)
 	b := &RandomWeightBalancer{
-		servers: atomic.Value{},
-		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:       rand.New(o.source),
+		override:  o.override,
+		onSelect:  o.onSelect,
+		fallback:  rngFallback{balancer: o.fallback},
+		maxWeight: maxWeight,
+		maxTotal:  maxTotalWeight,
+		slowStart: o.slowStart,
+		clock:     o.clock,
 	}
-	b.servers.Store(servers)
+	if len(o.probes) > 0 {
+		// Spread the probe slots evenly over each window of everyN calls
+		b.every = uint64(o.probeEvery)
+		b.probeAt = make(map[uint64]string, len(o.probes))
+		for i, addr := range o.probes {
+			b.probeAt[uint64(i*o.probeEvery/len(o.probes))] = addr
+		}
+	}
+	if o.maxSkew > 0 {
+		b.skew = newSkewGuard(o.maxSkew)
+	}
+	// Copy so the cached total cannot go stale when the caller mutates a
+	// Server after construction
+	b.servers.Store(newWeightedSnapshot(cloneServers(servers)))
 	return b
 }
 
-func (r *RandomWeightBalancer) Next() string {
-	// Read server list once to avoid race conditions
-	servers := r.servers.Load().([]*Server)
+// weightedSnapshot pairs a server list with its total positive weight, so
+// Next reads both from one atomic load and never re-sums the weights.
+type weightedSnapshot struct {
+	servers []*Server
+	total   int64
+	// overflow is set when the total does not fit in int64; only balancers
+	// built without validation can get there
+	overflow bool
+
+	// base holds the configured weights when servers were scaled by
+	// ScaleWeights; nil means servers are the configured weights
+	base []*Server
+
+	// When each still-warming server was added, and when the last of them
+	// finishes warming up. Only set with WithSlowStart.
+	addedAt   map[string]time.Time
+	rampUntil time.Time
+}
+
+func newWeightedSnapshot(servers []*Server) *weightedSnapshot {
+	total, ok := sumWeights(servers)
+	return &weightedSnapshot{servers: servers, total: total, overflow: !ok}
+}
+
+// configured returns the servers at their configured, unscaled weights.
+func (s *weightedSnapshot) configured() []*Server {
+	if s.base != nil {
+		return s.base
+	}
+	return s.servers
+}
+
+// sumWeights sums the positive weights, reporting false on int64 overflow.
+func sumWeights(servers []*Server) (int64, bool) {
+	var total int64
+	for _, s := range servers {
+		if s.Weight <= 0 {
+			continue
+		}
+		var ok bool
+		if total, ok = addWeight(total, int64(s.Weight)); !ok {
+			return total, false
+		}
+	}
+	return total, true
+}
+
+// NewRandomWeightBalancerWithSeed seeds the rng with seed, so two balancers
+// built with the same seed and servers yield the same selection sequence.
+// Intended for reproducible tests; production code should keep the default
+// time-seeded source.
+func NewRandomWeightBalancerWithSeed(servers []*Server, seed int64, opts ...Option) Balancer {
+	return NewRandomWeightBalancer(servers, slices.Concat(opts, []Option{WithRandSource(rand.NewSource(seed))})...)
+}
+
+// NewRandomWeightBalancerE validates the weights against the same limits as
+// the smooth balancer (maxWeight per server, maxTotalWeight in total) and
+// returns an error instead of building a balancer that cannot select. Empty
+// servers yield ErrEmptyPool and a repeated address is rejected, see
+// DuplicatePolicy for merging duplicates instead.
+func NewRandomWeightBalancerE(servers []*Server, opts ...Option) (Balancer, error) {
 	if len(servers) == 0 {
-		return ""
+		return nil, ErrEmptyPool
 	}
+	if err := checkDuplicates(servers, serverAddr); err != nil {
+		return nil, err
+	}
+	if err := validateWeights(servers, maxWeight, maxTotalWeight); err != nil {
+		return nil, err
+	}
+	return NewRandomWeightBalancer(servers, opts...), nil
+}
 
-	// Calculate total weight
-	totalWeight := 0
+// NewRandomWeightBalancerWithLimits validates every weight against maxWeight
+// and the aggregate against maxTotal. Totals are summed in int64 with an
+// explicit overflow check, so caps far above maxTotalWeight are safe. Empty
+// servers yield ErrEmptyPool and a repeated address is rejected.
+func NewRandomWeightBalancerWithLimits(servers []*Server, maxWeight, maxTotal int64) (Balancer, error) {
+	if len(servers) == 0 {
+		return nil, ErrEmptyPool
+	}
+	if err := checkDuplicates(servers, serverAddr); err != nil {
+		return nil, err
+	}
+	if err := validateWeights(servers, maxWeight, maxTotal); err != nil {
+		return nil, err
+	}
+	b := NewRandomWeightBalancer(servers).(*RandomWeightBalancer)
+	b.maxWeight, b.maxTotal = maxWeight, maxTotal
+	return b, nil
+}
+
+func validateWeights(servers []*Server, maxWeight, maxTotal int64) error {
+	var total int64
 	for _, s := range servers {
-		totalWeight += s.Weight
+		w := int64(s.Weight)
+		if w < 0 {
+			return fmt.Errorf("server %s weight must not be negative, got: %d", s.Addr, w)
+		}
+		if w > maxWeight {
+			return fmt.Errorf("server %s weight %d exceeds max %d", s.Addr, w, maxWeight)
+		}
+		var ok bool
+		if total, ok = addWeight(total, w); !ok {
+			return fmt.Errorf("total weight overflows int64")
+		}
+	}
+	if total > maxTotal {
+		return fmt.Errorf("total weight %d exceeds max %d", total, maxTotal)
+	}
+	return nil
+}
+
+// addWeight adds a non-negative weight to total, reporting false on int64 overflow.
+func addWeight(total, w int64) (int64, bool) {
+	if w > 0 && total > math.MaxInt64-w {
+		return total, false
+	}
+	return total + w, true
+}
+
+// SetServers atomically replaces the server set. The servers are validated
+// against the balancer's limits (maxWeight/maxTotalWeight, or those given to
+// NewRandomWeightBalancerWithLimits) and deep-copied, so in-flight Next calls
+// keep seeing the old snapshot and later changes by the caller never leak in.
+func (r *RandomWeightBalancer) SetServers(servers []*Server) error {
+	if err := validateWeights(servers, r.maxWeight, r.maxTotal); err != nil {
+		return err
+	}
+	copied := cloneServers(servers)
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
+
+	snap := newWeightedSnapshot(copied)
+	if r.slowStart > 0 {
+		r.trackAdded(snap, r.servers.Load())
+	}
+	r.servers.Store(snap)
+	return nil
+}
+
+// ScaleWeights sets every server's effective weight to its configured weight
+// times factor, rounded and clamped to at least 1, e.g. 0.5 to shed load
+// under global overload. The factor applies to the configured weights rather
+// than compounding, so ScaleWeights(1) restores them; SetServers resets it.
+// Ratios are preserved up to rounding and the clamp. Disabled (zero weight)
+// servers stay disabled. The scaled weights are validated against the same
+// limits as SetServers and the whole snapshot is swapped at once.
+func (r *RandomWeightBalancer) ScaleWeights(factor float64) error {
+	if !(factor > 0) || math.IsInf(factor, 1) {
+		return fmt.Errorf("scale factor must be positive and finite, got: %v", factor)
+	}
+	r.writeLock.Lock()
+	defer r.writeLock.Unlock()
+
+	prev := r.servers.Load()
+	base := prev.configured()
+	scaled := make([]*Server, len(base))
+	for i, s := range base {
+		c := *s
+		if s.Weight > 0 {
+			w := math.Round(float64(s.Weight) * factor)
+			if w > float64(r.maxWeight) {
+				return fmt.Errorf("server %s scaled weight %.0f exceeds max %d", s.Addr, w, r.maxWeight)
+			}
+			c.Weight = max(int(w), 1)
+		}
+		scaled[i] = &c
+	}
+	if err := validateWeights(scaled, r.maxWeight, r.maxTotal); err != nil {
+		return err
+	}
+
+	snap := newWeightedSnapshot(scaled)
+	if factor != 1 {
+		snap.base = base
+	}
+	snap.addedAt, snap.rampUntil = prev.addedAt, prev.rampUntil
+	r.servers.Store(snap)
+	return nil
+}
+
+// cloneServers deep-copies servers, including Types and Tags.
+func cloneServers(servers []*Server) []*Server {
+	copied := make([]*Server, 0, len(servers))
+	for _, s := range servers {
+		copied = append(copied, cloneServer(s))
+	}
+	return copied
+}
+
+// cloneServer deep-copies a single server.
+func cloneServer(s *Server) *Server {
+	c := *s
+	c.Types = slices.Clone(s.Types)
+	c.Tags = maps.Clone(s.Tags)
+	return &c
+}
+
+// trackAdded records when each server in snap that is new relative to prev
+// was added, carrying over the timestamps of servers still warming up.
+func (r *RandomWeightBalancer) trackAdded(snap, prev *weightedSnapshot) {
+	known := make(map[string]bool, len(prev.servers))
+	for _, s := range prev.servers {
+		known[s.Addr] = true
+	}
+	now := r.clock.Now()
+	for _, s := range snap.servers {
+		at, warming := prev.addedAt[s.Addr]
+		switch {
+		case !known[s.Addr]:
+			at = now
+		case !warming || !now.Before(at.Add(r.slowStart)):
+			continue
+		}
+		if snap.addedAt == nil {
+			snap.addedAt = make(map[string]time.Time)
+		}
+		snap.addedAt[s.Addr] = at
+		if until := at.Add(r.slowStart); until.After(snap.rampUntil) {
+			snap.rampUntil = until
+		}
+	}
+}
+
+// rampWeights returns copies of the warming servers with their weight scaled
+// by the elapsed share of the slow-start window, never below 1. Servers that
+// are warm are returned as is.
+func (r *RandomWeightBalancer) rampWeights(servers []*Server, addedAt map[string]time.Time, now time.Time) []*Server {
+	ramped := make([]*Server, len(servers))
+	for i, s := range servers {
+		ramped[i] = s
+		at, ok := addedAt[s.Addr]
+		if !ok || s.Weight <= 0 {
+			continue
+		}
+		if elapsed := now.Sub(at); elapsed < r.slowStart {
+			c := *s
+			frac := float64(max(elapsed, 0)) / float64(r.slowStart)
+			c.Weight = max(int(math.Ceil(float64(s.Weight)*frac)), 1)
+			ramped[i] = &c
+		}
+	}
+	return ramped
+}
+
+func (r *RandomWeightBalancer) Next() string {
+	addr, _ := r.NextE()
+	return addr
+}
+
+// NextE returns ErrEmptyPool when there are no servers and
+// ErrAllUnavailable when no server has a positive weight.
+func (r *RandomWeightBalancer) NextE() (string, error) {
+	addr, err := r.next()
+	notifySelect(r.onSelect, addr, err)
+	return addr, err
+}
+
+func (r *RandomWeightBalancer) next() (string, error) {
+	return r.nextAmong(nil)
+}
+
+// nextAmong selects among the servers accepted by accept (nil accepts all).
+// Probes and WithMaxSkew are invariants over the whole Next sequence, so they
+// only apply to unfiltered selections.
+func (r *RandomWeightBalancer) nextAmong(accept func(s *Server) bool) (string, error) {
+	// Read the snapshot once so servers and total weight always agree
+	snap := r.servers.Load()
+	servers := snap.servers
+	if len(servers) == 0 {
+		return "", ErrEmptyPool
+	}
+
+	// The cached total is only valid for the full list at configured
+	// weights; recompute it when filtering dropped servers or some are
+	// still warming up
+	totalWeight, ok := snap.total, !snap.overflow
+	recompute := false
+	if healthy := r.healthyServers(servers); len(healthy) != len(servers) {
+		servers = healthy
+		recompute = true
+	}
+	if accept != nil {
+		servers = slices.DeleteFunc(slices.Clone(servers), func(s *Server) bool {
+			return !accept(s)
+		})
+		recompute = true
+	}
+	if r.slowStart > 0 && !snap.rampUntil.IsZero() {
+		if now := r.clock.Now(); now.Before(snap.rampUntil) {
+			servers = r.rampWeights(servers, snap.addedAt, now)
+			recompute = true
+		}
+	}
+	if recompute {
+		totalWeight, ok = sumWeights(servers)
+	}
+	if !ok {
+		return "", ErrWeightOverflow
 	}
 	if totalWeight <= 0 {
-		return ""
+		return "", ErrAllUnavailable
 	}
 
-	// Generate random index with lock protection
-	r.lock.Lock()
-	idx := r.rng.Intn(totalWeight)
-	r.lock.Unlock()
+	// Candidates are the servers with a positive weight
+	if addr, ok := applyOverride(r.override, func() []string {
+		return positiveAddrs(servers)
+	}); ok {
+		return addr, nil
+	}
+
+	if r.every > 0 && accept == nil {
+		// servers is already health filtered; a probe address removed or
+		// disabled by SetServers is skipped like any other
+		if addr, ok := r.probeAt[(r.calls.Add(1)-1)%r.every]; ok && slices.ContainsFunc(servers, func(s *Server) bool {
+			return s.Addr == addr && s.Weight > 0
+		}) {
+			return addr, nil
+		}
+	}
+
+	addr := r.pick(servers, totalWeight, accept == nil)
+	if r.skew != nil && accept == nil {
+		addr = r.skew.adjust(addr, servers)
+	}
+	return addr, nil
+}
+
+// pick does the weighted random scan. useBalancer reports whether a
+// WithRNGFallback balancer may stand in when the rand.Source fails; it does
+// not know about filters, so filtered selections walk the set instead.
+func (r *RandomWeightBalancer) pick(servers []*Server, totalWeight int64, useBalancer bool) string {
+	// Generate random index with lock protection. If the rand.Source fails,
+	// walk the weight-expanded set in order instead.
+	idx, ok := safeInt63n(&r.lock, r.rng, totalWeight)
+	if !ok {
+		if useBalancer && r.fallback.balancer != nil {
+			return r.fallback.balancer.Next()
+		}
+		idx = r.fallback.next(totalWeight)
+	}
 
 	// Find the server based on random index
 	for _, s := range servers {
-		idx -= s.Weight
+		if s.Weight <= 0 {
+			continue
+		}
+		idx -= int64(s.Weight)
 		if idx < 0 {
 			return s.Addr
 		}
@@ -60,3 +454,193 @@ func (r *RandomWeightBalancer) Next() string {
 	// Return first server as fallback
 	return servers[0].Addr
 }
+
+// NextWithBias selects like Next, but adds extra[addr] on top of the
+// configured weight for this one call, e.g. to steer cache warmers towards a
+// node. Stored weights are never changed. Addresses not in the balancer and
+// non-positive extras are ignored, and disabled (zero weight) or unhealthy
+// servers stay unselectable. Overrides, probes and WithMaxSkew do not apply.
+// It returns "" when no server can be selected.
+func (r *RandomWeightBalancer) NextWithBias(extra map[string]int) string {
+	servers := r.healthyServers(r.servers.Load().servers)
+	weights := make([]int64, len(servers))
+	var total int64
+	for i, s := range servers {
+		if s.Weight <= 0 {
+			continue
+		}
+		w, ok := addWeight(int64(s.Weight), int64(max(extra[s.Addr], 0)))
+		if ok {
+			total, ok = addWeight(total, w)
+		}
+		if !ok {
+			return ""
+		}
+		weights[i] = w
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	idx, ok := safeInt63n(&r.lock, r.rng, total)
+	if !ok {
+		idx = r.fallback.next(total)
+	}
+	for i, w := range weights {
+		idx -= w
+		if w > 0 && idx < 0 {
+			notifySelect(r.onSelect, servers[i].Addr, nil)
+			return servers[i].Addr
+		}
+	}
+	return ""
+}
+
+// SetHealthy marks addr healthy or unhealthy. Unhealthy servers are left out
+// of the total weight and never selected; when every server is unhealthy
+// Next returns "" and NextE returns ErrAllUnavailable.
+func (r *RandomWeightBalancer) SetHealthy(addr string, healthy bool) {
+	r.healthLock.Lock()
+	defer r.healthLock.Unlock()
+
+	next := make(map[string]bool)
+	if cur := r.unhealthy.Load(); cur != nil {
+		for a := range *cur {
+			next[a] = true
+		}
+	}
+	if healthy {
+		delete(next, addr)
+	} else {
+		next[addr] = true
+	}
+	r.unhealthy.Store(&next)
+}
+
+func (r *RandomWeightBalancer) isHealthy(addr string) bool {
+	down := r.unhealthy.Load()
+	return down == nil || !(*down)[addr]
+}
+
+// healthyServers filters out unhealthy servers. It returns servers as is,
+// without allocating, when nothing is marked unhealthy.
+func (r *RandomWeightBalancer) healthyServers(servers []*Server) []*Server {
+	down := r.unhealthy.Load()
+	if down == nil || len(*down) == 0 {
+		return servers
+	}
+	healthy := make([]*Server, 0, len(servers))
+	for _, s := range servers {
+		if !(*down)[s.Addr] {
+			healthy = append(healthy, s)
+		}
+	}
+	return healthy
+}
+
+// NextForType does weighted random selection among the servers whose Types
+// include reqType, going through the same override, slow start, RNG
+// fallback and WithOnSelect handling as NextE. It returns
+// ErrNoServerForType when no available server supports it.
+func (r *RandomWeightBalancer) NextForType(reqType string) (string, error) {
+	addr, err := r.nextAmong(func(s *Server) bool {
+		return slices.Contains(s.Types, reqType)
+	})
+	if errors.Is(err, ErrAllUnavailable) {
+		err = fmt.Errorf("%w: %s", ErrNoServerForType, reqType)
+	}
+	notifySelect(r.onSelect, addr, err)
+	return addr, err
+}
+
+// NextMatching does weighted random selection among the healthy servers whose
+// Tags contain every key/value in selector; an empty selector matches all.
+// Weight is redistributed among the matches, and selection goes through the
+// same path as NextForType. It returns "" when nothing matches.
+func (r *RandomWeightBalancer) NextMatching(selector map[string]string) string {
+	addr, err := r.nextAmong(func(s *Server) bool {
+		for k, v := range selector {
+			if tag, ok := s.Tags[k]; !ok || tag != v {
+				return false
+			}
+		}
+		return true
+	})
+	notifySelect(r.onSelect, addr, err)
+	return addr
+}
+
+// weightedPick runs the weighted random scan over the servers accepted by
+// available (nil accepts all). It returns "" when no accepted server has a
+// positive weight or the accepted total overflows int64. If rng panics, the
+// index comes from fb instead. The caller must hold whatever lock protects
+// rng.
+func weightedPick(rng *rand.Rand, fb *rngFallback, servers []*Server, available func(s *Server) bool) string {
+	var total int64
+	for _, s := range servers {
+		if s.Weight > 0 && (available == nil || available(s)) {
+			var ok bool
+			if total, ok = addWeight(total, int64(s.Weight)); !ok {
+				return ""
+			}
+		}
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	idx, ok := tryInt63n(rng, total)
+	if !ok {
+		idx = fb.next(total)
+	}
+	for _, s := range servers {
+		if s.Weight <= 0 || (available != nil && !available(s)) {
+			continue
+		}
+		idx -= int64(s.Weight)
+		if idx < 0 {
+			return s.Addr
+		}
+	}
+	return ""
+}
+
+// Servers returns a copy of the server addresses, including servers with a
+// zero weight or marked unhealthy.
+func (r *RandomWeightBalancer) Servers() []string {
+	servers := r.servers.Load().servers
+	addrs := make([]string, 0, len(servers))
+	for _, s := range servers {
+		addrs = append(addrs, s.Addr)
+	}
+	return addrs
+}
+
+// FirstAvailable returns the first server, in configuration order, with a
+// positive weight that is not marked unhealthy, or "" if there is none. It is
+// a deterministic fallback for callers that need some address when the
+// weighted selection is not wanted or fails.
+func (r *RandomWeightBalancer) FirstAvailable() string {
+	for _, s := range r.servers.Load().servers {
+		if s.Weight > 0 && r.isHealthy(s.Addr) {
+			return s.Addr
+		}
+	}
+	return ""
+}
+
+// Len returns the number of servers.
+func (r *RandomWeightBalancer) Len() int {
+	return len(r.servers.Load().servers)
+}
+
+// positiveAddrs returns the addresses of servers with a positive weight.
+func positiveAddrs(servers []*Server) []string {
+	addrs := make([]string, 0, len(servers))
+	for _, s := range servers {
+		if s.Weight > 0 {
+			addrs = append(addrs, s.Addr)
+		}
+	}
+	return addrs
+}
@@ -1,8 +1,11 @@
 package balance
 
 import (
+	"math"
+	"slices"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestRandomWeightBalancer_Basic(t *testing.T) {
@@ -11,7 +14,8 @@ func TestRandomWeightBalancer_Basic(t *testing.T) {
 		{Addr: "server2", Weight: 20},
 		{Addr: "server3", Weight: 30},
 	}
-	balancer := NewRandomWeightBalancer(servers)
+	// Fixed seed keeps the ratio checks below from failing on an unlucky run
+	balancer := NewRandomWeightBalancerWithSeed(servers, 1)
 
 	// Run multiple times to ensure all servers are selected
 	results := make(map[string]int)
@@ -93,7 +97,7 @@ func TestRandomWeightBalancer_EqualWeights(t *testing.T) {
 		{Addr: "server2", Weight: 10},
 		{Addr: "server3", Weight: 10},
 	}
-	balancer := NewRandomWeightBalancer(servers)
+	balancer := NewRandomWeightBalancerWithSeed(servers, 1)
 
 	results := make(map[string]int)
 	iterations := 3000
@@ -311,3 +315,483 @@ func TestRandomWeightBalancer_Consistency(t *testing.T) {
 		}
 	}
 }
+
+func TestRandomWeightBalancer_SetHealthy(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 30},
+		{Addr: "server3", Weight: 60},
+	}
+	balancer := NewRandomWeightBalancer(servers).(*RandomWeightBalancer)
+	balancer.SetHealthy("server3", false)
+
+	results := make(map[string]int)
+	for i := 0; i < 8000; i++ {
+		addr := balancer.Next()
+		if addr == "server3" {
+			t.Fatal("unhealthy server3 was selected")
+		}
+		results[addr]++
+	}
+	ratio := float64(results["server2"]) / float64(results["server1"])
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected server2/server1 ratio around 3.0, got %.2f (%v)", ratio, results)
+	}
+
+	// 全部不健康时返回空字符串
+	balancer.SetHealthy("server1", false)
+	balancer.SetHealthy("server2", false)
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty string when all unhealthy", got)
+	}
+	if _, err := balancer.NextE(); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+
+	// 恢复后重新参与选择
+	balancer.SetHealthy("server3", true)
+	if got := balancer.Next(); got != "server3" {
+		t.Errorf("Next() = %v, want server3 after recovery", got)
+	}
+}
+
+func TestRandomWeightBalancer_SetServers(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{{Addr: "old", Weight: 1}}).(*RandomWeightBalancer)
+
+	next := []*Server{{Addr: "new1", Weight: 1}, {Addr: "new2", Weight: 3}}
+	if err := balancer.SetServers(next); err != nil {
+		t.Fatalf("SetServers() error = %v", err)
+	}
+	// 调用方之后的修改不影响负载均衡器
+	next[0].Addr = "mutated"
+	next[1].Weight = 0
+	results := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		results[balancer.Next()]++
+	}
+	if results["old"] != 0 || results["mutated"] != 0 || results["new1"] == 0 || results["new2"] < 2*results["new1"] {
+		t.Errorf("unexpected distribution after SetServers: %v", results)
+	}
+
+	// 非法的权重被拒绝，保留原来的服务器
+	if err := balancer.SetServers([]*Server{{Addr: "bad", Weight: maxWeight + 1}}); err == nil {
+		t.Error("SetServers() with weight above max should fail")
+	}
+	if got := balancer.Next(); got != "new1" && got != "new2" {
+		t.Errorf("Next() = %v after rejected SetServers", got)
+	}
+}
+
+func TestRandomWeightBalancer_SetServersConcurrent(t *testing.T) {
+	sets := [][]*Server{
+		{{Addr: "a1", Weight: 1}, {Addr: "a2", Weight: 2}},
+		{{Addr: "b1", Weight: 5}},
+		{{Addr: "c1", Weight: 1}, {Addr: "c2", Weight: 1}, {Addr: "c3", Weight: 0}},
+	}
+	valid := map[string]bool{"a1": true, "a2": true, "b1": true, "c1": true, "c2": true}
+	balancer := NewRandomWeightBalancer(sets[0]).(*RandomWeightBalancer)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if addr := balancer.Next(); !valid[addr] {
+					t.Errorf("Next() = %q, want a valid address", addr)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 2000; i++ {
+		if err := balancer.SetServers(sets[i%len(sets)]); err != nil {
+			t.Fatalf("SetServers() error = %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRandomWeightBalancer_ZeroWeightDisabled(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "disabled1", Weight: 0},
+		{Addr: "server1", Weight: 1},
+		{Addr: "disabled2", Weight: 0},
+		{Addr: "server2", Weight: 2},
+	}).(*RandomWeightBalancer)
+
+	for i := 0; i < 1000; i++ {
+		if addr := balancer.Next(); addr == "disabled1" || addr == "disabled2" {
+			t.Fatalf("disabled server %s was selected", addr)
+		}
+	}
+
+	// 禁用的服务器仍然在列表中
+	servers := balancer.Servers()
+	if len(servers) != 4 || servers[0] != "disabled1" || servers[2] != "disabled2" {
+		t.Errorf("Servers() = %v, want all four including disabled", servers)
+	}
+
+	if got := balancer.FirstAvailable(); got != "server1" {
+		t.Errorf("FirstAvailable() = %v, want server1", got)
+	}
+	balancer.SetHealthy("server1", false)
+	if got := balancer.FirstAvailable(); got != "server2" {
+		t.Errorf("FirstAvailable() = %v, want server2 when server1 unhealthy", got)
+	}
+
+	allDisabled := NewRandomWeightBalancer([]*Server{{Addr: "a", Weight: 0}}).(*RandomWeightBalancer)
+	if got := allDisabled.FirstAvailable(); got != "" {
+		t.Errorf("FirstAvailable() = %v, want empty", got)
+	}
+}
+
+func TestRandomWeightBalancer_CachedTotal(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "server1", Weight: 1},
+		{Addr: "server2", Weight: 3},
+		{Addr: "server3", Weight: 0},
+	}).(*RandomWeightBalancer)
+	if got := balancer.servers.Load().total; got != 4 {
+		t.Fatalf("cached total = %d, want 4", got)
+	}
+
+	// SetServers 重新计算总权重
+	if err := balancer.SetServers([]*Server{{Addr: "server1", Weight: 1}, {Addr: "server2", Weight: 1}}); err != nil {
+		t.Fatalf("SetServers() error = %v", err)
+	}
+	if got := balancer.servers.Load().total; got != 2 {
+		t.Fatalf("cached total after SetServers = %d, want 2", got)
+	}
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.Next()]++
+	}
+	ratio := float64(results["server2"]) / float64(results["server1"])
+	if ratio < 0.85 || ratio > 1.15 {
+		t.Errorf("Expected server2/server1 ratio around 1.0, got %.2f (%v)", ratio, results)
+	}
+
+	// 有不健康的服务器时不使用缓存的总权重
+	balancer.SetHealthy("server1", false)
+	for i := 0; i < 100; i++ {
+		if got := balancer.Next(); got != "server2" {
+			t.Fatalf("Next() = %v, want server2 while server1 unhealthy", got)
+		}
+	}
+}
+
+func BenchmarkRandomWeightBalancer_10(b *testing.B) {
+	balancer := NewRandomWeightBalancer(benchmarkServers(10))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		balancer.Next()
+	}
+}
+
+func TestRandomWeightBalancer_WithSeed(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 1},
+		{Addr: "server2", Weight: 2},
+		{Addr: "server3", Weight: 3},
+	}
+	balancer := NewRandomWeightBalancerWithSeed(servers, 42)
+
+	want := []string{"server2", "server2", "server1", "server3", "server3", "server3", "server3", "server1", "server2", "server2"}
+	for i, w := range want {
+		if got := balancer.Next(); got != w {
+			t.Fatalf("call %d: Next() = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestRandomWeightBalancer_NextWithBias(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 30},
+		{Addr: "server3", Weight: 0},
+	}
+	balancer := NewRandomWeightBalancerWithSeed(servers, 1).(*RandomWeightBalancer)
+
+	// A large bias makes server1 dominate
+	results := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		results[balancer.NextWithBias(map[string]int{"server1": 10_000, "unknown": 10_000})]++
+	}
+	if share := float64(results["server1"]) / 4000; share < 0.99 {
+		t.Errorf("server1 share = %.3f with large bias, want > 0.99 (%v)", share, results)
+	}
+
+	// A small bias still leaves the base weights in charge: 20 vs 30
+	results = make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.NextWithBias(map[string]int{"server1": 10, "server3": 1000})]++
+	}
+	ratio := float64(results["server2"]) / float64(results["server1"])
+	if ratio < 1.3 || ratio > 1.7 {
+		t.Errorf("Expected server2/server1 ratio around 1.5, got %.2f (%v)", ratio, results)
+	}
+	if results["server3"] != 0 {
+		t.Errorf("disabled server3 selected %d times through bias", results["server3"])
+	}
+
+	// Stored weights are untouched
+	if got := balancer.Probabilities()["server1"]; got != 0.25 {
+		t.Errorf("Probabilities()[server1] = %v after NextWithBias, want 0.25", got)
+	}
+}
+
+func TestRandomWeightBalancer_NextMatching(t *testing.T) {
+	servers := []*Server{
+		{Addr: "v1-a", Weight: 10, Tags: map[string]string{"version": "v1"}},
+		{Addr: "v2-a", Weight: 10, Tags: map[string]string{"version": "v2", "gpu": "true"}},
+		{Addr: "v2-b", Weight: 30, Tags: map[string]string{"version": "v2"}},
+		{Addr: "untagged", Weight: 10},
+	}
+	balancer := NewRandomWeightBalancerWithSeed(servers, 1).(*RandomWeightBalancer)
+
+	results := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		results[balancer.NextMatching(map[string]string{"version": "v2"})]++
+	}
+	if len(results) != 2 || results["v2-a"] == 0 {
+		t.Fatalf("NextMatching(version=v2) = %v, want only v2 servers", results)
+	}
+	ratio := float64(results["v2-b"]) / float64(results["v2-a"])
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected v2-b/v2-a ratio around 3.0, got %.2f (%v)", ratio, results)
+	}
+
+	// All selector pairs must match
+	for i := 0; i < 100; i++ {
+		if got := balancer.NextMatching(map[string]string{"version": "v2", "gpu": "true"}); got != "v2-a" {
+			t.Fatalf("NextMatching(version=v2,gpu=true) = %v, want v2-a", got)
+		}
+	}
+	if got := balancer.NextMatching(map[string]string{"version": "v3"}); got != "" {
+		t.Errorf("NextMatching(version=v3) = %v, want empty string", got)
+	}
+	if got := balancer.NextMatching(nil); got == "" {
+		t.Error("NextMatching(nil) should match every server")
+	}
+}
+
+func TestRandomWeightBalancer_NextMatchingSharedPath(t *testing.T) {
+	servers := []*Server{
+		{Addr: "v1", Weight: 1, Tags: map[string]string{"version": "v1"}},
+		{Addr: "v2-a", Weight: 1, Tags: map[string]string{"version": "v2"}},
+		{Addr: "v2-b", Weight: 1, Tags: map[string]string{"version": "v2"}},
+	}
+	selector := map[string]string{"version": "v2"}
+
+	// 随机源故障时不会 panic，在匹配的服务器上轮询
+	balancer := NewRandomWeightBalancer(servers, WithRandSource(failingSource{})).(*RandomWeightBalancer)
+	for i, w := range []string{"v2-a", "v2-b", "v2-a"} {
+		if got := balancer.NextMatching(selector); got != w {
+			t.Fatalf("call %d: NextMatching() = %v, want %v", i, got, w)
+		}
+	}
+
+	// 覆盖钩子看到的候选集只包含匹配的服务器
+	var candidates []string
+	balancer = NewRandomWeightBalancer(servers, WithDecisionOverride(func(c []string) (string, bool) {
+		candidates = c
+		return "", false
+	})).(*RandomWeightBalancer)
+	balancer.NextMatching(selector)
+	if !slices.Equal(candidates, []string{"v2-a", "v2-b"}) {
+		t.Errorf("override candidates = %v, want [v2-a v2-b]", candidates)
+	}
+
+	// 总权重溢出时不选择
+	overflow := NewRandomWeightBalancer([]*Server{
+		{Addr: "a", Weight: math.MaxInt64},
+		{Addr: "b", Weight: math.MaxInt64},
+	}).(*RandomWeightBalancer)
+	if got := overflow.NextMatching(nil); got != "" {
+		t.Errorf("NextMatching() = %v with overflowing weights, want empty", got)
+	}
+}
+
+func TestRandomWeightBalancer_SlowStart(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewRandomWeightBalancerWithSeed([]*Server{{Addr: "old", Weight: 10}}, 1,
+		WithSlowStart(10*time.Second), WithClock(clock)).(*RandomWeightBalancer)
+
+	if err := balancer.SetServers([]*Server{{Addr: "old", Weight: 10}, {Addr: "new", Weight: 90}}); err != nil {
+		t.Fatalf("SetServers() error = %v", err)
+	}
+
+	share := func() float64 {
+		hits := 0
+		for i := 0; i < 4000; i++ {
+			if balancer.Next() == "new" {
+				hits++
+			}
+		}
+		return float64(hits) / 4000
+	}
+
+	// Weight ramps 1 -> 23 -> 45 -> 90 against old's 10
+	var shares []float64
+	for _, step := range []time.Duration{0, 2500 * time.Millisecond, 2500 * time.Millisecond, 5 * time.Second} {
+		clock.Advance(step)
+		shares = append(shares, share())
+	}
+	t.Logf("new server share over the window: %.3f", shares)
+	if shares[0] > 0.15 {
+		t.Errorf("new server share right after SetServers = %.3f, want a trickle", shares[0])
+	}
+	for i := 1; i < len(shares); i++ {
+		if shares[i] <= shares[i-1] {
+			t.Errorf("share did not grow: %.3f", shares)
+			break
+		}
+	}
+	if shares[3] < 0.85 {
+		t.Errorf("share after the window = %.3f, want full weight (~0.9)", shares[3])
+	}
+
+	// A server already present keeps its warm-up progress across SetServers
+	if err := balancer.SetServers([]*Server{{Addr: "old", Weight: 10}, {Addr: "new", Weight: 90}, {Addr: "newer", Weight: 90}}); err != nil {
+		t.Fatalf("SetServers() error = %v", err)
+	}
+	if at, ok := balancer.servers.Load().addedAt["new"]; ok {
+		t.Errorf("warm server new still tracked as added at %v", at)
+	}
+	if _, ok := balancer.servers.Load().addedAt["newer"]; !ok {
+		t.Error("newer should be warming up")
+	}
+}
+
+func TestRandomWeightBalancer_ScaleWeights(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 30},
+		{Addr: "server3", Weight: 60},
+		{Addr: "off", Weight: 0},
+	}
+	balancer := NewRandomWeightBalancer(servers).(*RandomWeightBalancer)
+	before := balancer.Probabilities()
+
+	if err := balancer.ScaleWeights(0.5); err != nil {
+		t.Fatalf("ScaleWeights(0.5) error = %v", err)
+	}
+	if got := balancer.servers.Load().total; got != 50 {
+		t.Errorf("total after ScaleWeights(0.5) = %d, want 50", got)
+	}
+	after := balancer.Probabilities()
+	for addr, p := range before {
+		if math.Abs(after[addr]-p) > 1e-9 {
+			t.Errorf("probability of %s = %v after scaling, want %v", addr, after[addr], p)
+		}
+	}
+
+	// Factors do not compound, and tiny factors clamp every enabled server at 1
+	if err := balancer.ScaleWeights(0.01); err != nil {
+		t.Fatalf("ScaleWeights(0.01) error = %v", err)
+	}
+	if got := balancer.servers.Load().total; got != 3 {
+		t.Errorf("total after ScaleWeights(0.01) = %d, want 3", got)
+	}
+	if got := balancer.Probabilities()["off"]; got != 0 {
+		t.Errorf("disabled server probability = %v after scaling, want 0", got)
+	}
+	if err := balancer.ScaleWeights(1); err != nil {
+		t.Fatalf("ScaleWeights(1) error = %v", err)
+	}
+	if got := balancer.servers.Load().total; got != 100 {
+		t.Errorf("total after ScaleWeights(1) = %d, want configured 100", got)
+	}
+
+	// Scaling past the limits is rejected and leaves the weights unchanged
+	limited, err := NewRandomWeightBalancerWithLimits(servers, 100, 150)
+	if err != nil {
+		t.Fatalf("NewRandomWeightBalancerWithLimits() error = %v", err)
+	}
+	if err := limited.(*RandomWeightBalancer).ScaleWeights(2); err == nil {
+		t.Error("ScaleWeights(2) above the total limit should fail")
+	}
+	if got := limited.(*RandomWeightBalancer).servers.Load().total; got != 100 {
+		t.Errorf("total after rejected ScaleWeights = %d, want 100", got)
+	}
+	for _, factor := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if err := balancer.ScaleWeights(factor); err == nil {
+			t.Errorf("ScaleWeights(%v) should fail", factor)
+		}
+	}
+}
+
+// Run with -race: readers must always see a snapshot whose cached total
+// matches its own servers, whatever writers swap in concurrently.
+func TestRandomWeightBalancer_SnapshotCoherence(t *testing.T) {
+	sets := [][]*Server{
+		{{Addr: "a1", Weight: 1}, {Addr: "a2", Weight: 2}},
+		{{Addr: "b1", Weight: 500}},
+		{{Addr: "c1", Weight: 7}, {Addr: "c2", Weight: 9}, {Addr: "c3", Weight: 0}},
+	}
+	valid := map[string]bool{"a1": true, "a2": true, "b1": true, "c1": true, "c2": true}
+	balancer := NewRandomWeightBalancer(sets[0]).(*RandomWeightBalancer)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if addr := balancer.Next(); !valid[addr] {
+					t.Errorf("Next() = %q, not in any server set", addr)
+					return
+				}
+				balancer.Probabilities()
+				snap := balancer.servers.Load()
+				if total, _ := sumWeights(snap.servers); total != snap.total {
+					t.Errorf("snapshot total = %d, servers sum to %d", snap.total, total)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 2000; i++ {
+		if err := balancer.SetServers(sets[i%len(sets)]); err != nil {
+			t.Fatalf("SetServers() error = %v", err)
+		}
+		if err := balancer.ScaleWeights(float64(i%4 + 1)); err != nil {
+			t.Fatalf("ScaleWeights() error = %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRandomWeightBalancer_CopiesServers(t *testing.T) {
+	servers := []*Server{
+		{Addr: "server1", Weight: 10},
+		{Addr: "server2", Weight: 10},
+	}
+	balancer := NewRandomWeightBalancer(servers)
+
+	// Later changes by the caller must not leak into the cached snapshot
+	servers[0].Weight = 0
+	servers[1].Addr = "mutated"
+	results := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		results[balancer.Next()]++
+	}
+	if results["mutated"] != 0 || results["server1"] == 0 || results["server2"] == 0 {
+		t.Errorf("caller mutation leaked into the balancer: %v", results)
+	}
+}
@@ -0,0 +1,62 @@
+package balance
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+type ringEntry[T any] struct {
+	value T
+	seq   uint64
+}
+
+// recentRing 保留最近 size 条记录的环形缓冲区，TraceBufferBalancer 和 HistoryBalancer 共用
+// 写入只有一次原子自增和一次 CAS，不加锁；慢的写入者不会用旧记录覆盖槽位里更新的记录
+type recentRing[T any] struct {
+	slots []atomic.Pointer[ringEntry[T]]
+	seq   atomic.Uint64
+}
+
+// newRecentRing size 小于1时按1处理
+func newRecentRing[T any](size int) *recentRing[T] {
+	return &recentRing[T]{
+		slots: make([]atomic.Pointer[ringEntry[T]], max(size, 1)),
+	}
+}
+
+func (r *recentRing[T]) add(v T) {
+	r.store(&ringEntry[T]{value: v, seq: r.seq.Add(1) - 1})
+}
+
+func (r *recentRing[T]) store(e *ringEntry[T]) {
+	slot := &r.slots[e.seq%uint64(len(r.slots))]
+	for {
+		old := slot.Load()
+		// 写入者在自增之后被挂起，槽位可能已经被后来的记录占用，此时丢弃这条旧记录
+		if old != nil && old.seq > e.seq {
+			return
+		}
+		if slot.CompareAndSwap(old, e) {
+			return
+		}
+	}
+}
+
+// snapshot 按写入顺序（从旧到新）返回当前保留的记录
+// 与写入并发时，可能缺少正在写入的那一条
+func (r *recentRing[T]) snapshot() []T {
+	entries := make([]*ringEntry[T], 0, len(r.slots))
+	for i := range r.slots {
+		if e := r.slots[i].Load(); e != nil {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	values := make([]T, len(entries))
+	for i, e := range entries {
+		values[i] = e.value
+	}
+	return values
+}
@@ -0,0 +1,19 @@
+package balance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecentRing_DropsStaleWrite(t *testing.T) {
+	ring := newRecentRing[string](2)
+	// 第一个写入者取得 seq 0 之后被挂起，后面的写入已经绕回到同一个槽位
+	stale := &ringEntry[string]{value: "stale", seq: ring.seq.Add(1) - 1}
+	ring.add("b")
+	ring.add("c")
+	ring.store(stale)
+
+	if got, want := ring.snapshot(), []string{"b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("snapshot() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package balance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRandomWeightBalancer_NextForType(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "gpu1", Weight: 10, Types: []string{"inference", "web"}},
+		{Addr: "gpu2", Weight: 30, Types: []string{"inference"}},
+		{Addr: "cpu1", Weight: 100, Types: []string{"web"}},
+	}).(*RandomWeightBalancer)
+
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		addr, err := balancer.NextForType("inference")
+		if err != nil {
+			t.Fatalf("NextForType() error = %v", err)
+		}
+		results[addr]++
+	}
+
+	if results["cpu1"] > 0 {
+		t.Errorf("unsupporting server selected: %v", results)
+	}
+	ratio := float64(results["gpu2"]) / float64(results["gpu1"])
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected gpu2/gpu1 ratio around 3.0, got %.2f", ratio)
+	}
+}
+
+func TestRandomWeightBalancer_NextForTypeUnsupported(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "cpu1", Weight: 1, Types: []string{"web"}},
+	}).(*RandomWeightBalancer)
+
+	if _, err := balancer.NextForType("inference"); !errors.Is(err, ErrNoServerForType) {
+		t.Errorf("NextForType() error = %v, want ErrNoServerForType", err)
+	}
+
+	empty := NewRandomWeightBalancer(nil).(*RandomWeightBalancer)
+	if _, err := empty.NextForType("web"); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("NextForType() error = %v, want ErrEmptyPool", err)
+	}
+}
+
+func TestRandomWeightBalancer_NextForTypeSharedPath(t *testing.T) {
+	servers := []*Server{
+		{Addr: "gpu1", Weight: 1, Types: []string{"inference"}},
+		{Addr: "gpu2", Weight: 1, Types: []string{"inference"}},
+		{Addr: "cpu1", Weight: 1, Types: []string{"web"}},
+	}
+
+	// WithOnSelect 同样收到按类型选择的结果
+	var selected []string
+	balancer := NewRandomWeightBalancer(servers, WithOnSelect(func(addr string) {
+		selected = append(selected, addr)
+	})).(*RandomWeightBalancer)
+	addr, _ := balancer.NextForType("inference")
+	if len(selected) != 1 || selected[0] != addr {
+		t.Errorf("onSelect got %v, want [%v]", selected, addr)
+	}
+
+	// 覆盖钩子看到的候选集只包含支持该类型的服务器
+	var candidates []string
+	balancer = NewRandomWeightBalancer(servers, WithDecisionOverride(func(c []string) (string, bool) {
+		candidates = c
+		return "", false
+	})).(*RandomWeightBalancer)
+	balancer.NextForType("inference")
+	if len(candidates) != 2 || candidates[0] != "gpu1" || candidates[1] != "gpu2" {
+		t.Errorf("override candidates = %v, want [gpu1 gpu2]", candidates)
+	}
+
+	// 随机源故障时在按类型过滤后的集合上轮询，不使用不感知类型的兜底负载均衡器
+	balancer = NewRandomWeightBalancer(servers, WithRandSource(failingSource{}),
+		WithRNGFallback(NewRoundRobinBalancer([]string{"cpu1"}))).(*RandomWeightBalancer)
+	want := []string{"gpu1", "gpu2", "gpu1", "gpu2"}
+	for i, w := range want {
+		if got, err := balancer.NextForType("inference"); err != nil || got != w {
+			t.Fatalf("call %d: NextForType() = %v, %v, want %v", i, got, err, w)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package balance
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ReservoirBalancer
+// 服务发现以流的形式推送服务器时，用蓄水池抽样维护一个最多 maxSize 个服务器的代表性子集
+// Next 在当前蓄水池内按权重随机
+type ReservoirBalancer struct {
+	maxSize   int
+	reservoir []*Server
+	seen      int64
+	rng       *rand.Rand
+	fallback  rngFallback
+	lock      sync.Mutex
+}
+
+func NewReservoirBalancer(maxSize int) *ReservoirBalancer {
+	return &ReservoirBalancer{
+		maxSize:   max(maxSize, 1),
+		reservoir: make([]*Server, 0, max(maxSize, 1)),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Offer 推送一个候选服务器
+// 已在蓄水池中的地址只更新权重；否则第 n 个候选以 maxSize/n 的概率替换池中随机一个
+// 权重按与 NewRandomWeightBalancerE 相同的规则校验：单个权重超出范围，或放入后蓄水池总权重超过 maxTotalWeight 时返回错误，蓄水池不变
+func (r *ReservoirBalancer) Offer(server *Server) error {
+	if err := validateWeights([]*Server{server}, maxWeight, maxTotalWeight); err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, s := range r.reservoir {
+		if s.Addr == server.Addr {
+			return r.put(i, server)
+		}
+	}
+
+	r.seen++
+	if len(r.reservoir) < r.maxSize {
+		return r.put(len(r.reservoir), server)
+	}
+	if j := r.rng.Int63n(r.seen); j < int64(r.maxSize) {
+		return r.put(int(j), server)
+	}
+	return nil
+}
+
+// put 把 server 放到位置 i（i 等于长度时追加），总权重超限时拒绝，调用方持有锁
+func (r *ReservoirBalancer) put(i int, server *Server) error {
+	total := int64(server.Weight)
+	for j, s := range r.reservoir {
+		if j != i {
+			total += int64(s.Weight)
+		}
+	}
+	if total > maxTotalWeight {
+		return fmt.Errorf("server %s: total weight %d exceeds max %d", server.Addr, total, maxTotalWeight)
+	}
+
+	c := &Server{Addr: server.Addr, Weight: server.Weight}
+	if i == len(r.reservoir) {
+		r.reservoir = append(r.reservoir, c)
+	} else {
+		r.reservoir[i] = c
+	}
+	return nil
+}
+
+// Len 当前蓄水池中的服务器数量
+func (r *ReservoirBalancer) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.reservoir)
+}
+
+func (r *ReservoirBalancer) Next() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return weightedPick(r.rng, &r.fallback, r.reservoir, nil)
+}
@@ -0,0 +1,81 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReservoirBalancer_Bounded(t *testing.T) {
+	balancer := NewReservoirBalancer(10)
+
+	for i := 0; i < 1000; i++ {
+		balancer.Offer(&Server{Addr: fmt.Sprintf("server%d", i), Weight: 1})
+		if balancer.Len() > 10 {
+			t.Fatalf("reservoir grew to %d", balancer.Len())
+		}
+	}
+	if balancer.Len() != 10 {
+		t.Errorf("Len() = %d, want 10", balancer.Len())
+	}
+}
+
+func TestReservoirBalancer_Weighted(t *testing.T) {
+	balancer := NewReservoirBalancer(3)
+	balancer.Offer(&Server{Addr: "a", Weight: 1})
+	balancer.Offer(&Server{Addr: "b", Weight: 2})
+	balancer.Offer(&Server{Addr: "c", Weight: 1})
+	// 重复推送只更新权重
+	balancer.Offer(&Server{Addr: "c", Weight: 3})
+
+	if balancer.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", balancer.Len())
+	}
+
+	results := make(map[string]int)
+	iterations := 60000
+	for i := 0; i < iterations; i++ {
+		results[balancer.Next()]++
+	}
+
+	expected := map[string]float64{"a": 1.0 / 6, "b": 2.0 / 6, "c": 3.0 / 6}
+	for addr, exp := range expected {
+		actual := float64(results[addr]) / float64(iterations)
+		if diff := actual - exp; diff < -0.02 || diff > 0.02 {
+			t.Errorf("%s: expected %.2f, got %.2f", addr, exp, actual)
+		}
+	}
+}
+
+func TestReservoirBalancer_Empty(t *testing.T) {
+	if got := NewReservoirBalancer(5).Next(); got != "" {
+		t.Errorf("Next() = %v, want empty", got)
+	}
+}
+
+func TestReservoirBalancer_InvalidWeights(t *testing.T) {
+	balancer := NewReservoirBalancer(20)
+	if err := balancer.Offer(&Server{Addr: "neg", Weight: -1}); err == nil {
+		t.Error("Offer(negative weight) error = nil, want error")
+	}
+	if err := balancer.Offer(&Server{Addr: "big", Weight: maxWeight + 1}); err == nil {
+		t.Error("Offer(weight > maxWeight) error = nil, want error")
+	}
+
+	// 总权重不能超过 maxTotalWeight
+	n := maxTotalWeight / maxWeight
+	for i := 0; i < n; i++ {
+		if err := balancer.Offer(&Server{Addr: fmt.Sprintf("s%d", i), Weight: maxWeight}); err != nil {
+			t.Fatalf("Offer(s%d) error = %v", i, err)
+		}
+	}
+	if err := balancer.Offer(&Server{Addr: "extra", Weight: 1}); err == nil {
+		t.Error("Offer over maxTotalWeight error = nil, want error")
+	}
+	// 更新已有地址的权重时，不计算它原来的权重
+	if err := balancer.Offer(&Server{Addr: "s0", Weight: 1}); err != nil {
+		t.Errorf("Offer(update s0) error = %v", err)
+	}
+	if got := balancer.Len(); got != n {
+		t.Errorf("Len() = %d, want %d", got, n)
+	}
+}
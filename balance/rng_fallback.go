@@ -0,0 +1,38 @@
+package balance
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// rngFallback 随机源故障时的兜底
+// balancer 为 WithRNGFallback 指定的负载均衡器；为空时调用方用 next 在同一个集合上轮询
+type rngFallback struct {
+	balancer Balancer
+	index    atomic.Uint64
+}
+
+// next 返回 [0, n) 内依次递增的下标
+// 对加权集合，n 为总权重，下标按累积权重映射回服务器，相当于在按权重展开的列表上轮询
+func (f *rngFallback) next(n int64) int64 {
+	return int64((f.index.Add(1) - 1) % uint64(n))
+}
+
+// safeInt63n 持锁调用 rng.Int63n，随机源 panic 时返回 false
+// 用 defer 释放锁，保证 panic 之后锁不会一直被占用
+func safeInt63n(mu sync.Locker, rng *rand.Rand, n int64) (int64, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	return tryInt63n(rng, n)
+}
+
+// tryInt63n 与 safeInt63n 相同，但由调用方持有保护 rng 的锁
+func tryInt63n(rng *rand.Rand, n int64) (v int64, ok bool) {
+	defer func() {
+		if recover() != nil {
+			v, ok = 0, false
+		}
+	}()
+	return rng.Int63n(n), true
+}
@@ -0,0 +1,79 @@
+package balance
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// failingSource 模拟熵源故障
+type failingSource struct{}
+
+func (failingSource) Int63() int64 { panic("entropy source unavailable") }
+func (failingSource) Seed(int64)   {}
+
+func TestRandomBalancer_RNGFallback(t *testing.T) {
+	balancer := NewRandomBalancer([]string{"s1", "s2", "s3"}, WithRandSource(failingSource{}))
+
+	want := []string{"s1", "s2", "s3", "s1", "s2", "s3"}
+	for i, w := range want {
+		got, err := balancer.(ErrorBalancer).NextE()
+		if err != nil || got != w {
+			t.Fatalf("call %d: NextE() = %v, %v, want %v, nil", i, got, err, w)
+		}
+	}
+}
+
+func TestRandomWeightBalancer_RNGFallback(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "s1", Weight: 3},
+		{Addr: "s2", Weight: 1},
+		{Addr: "s3", Weight: 0},
+	}, WithRandSource(failingSource{}))
+
+	results := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		addr := balancer.Next()
+		if addr == "" {
+			t.Fatal("Next() returned empty string under RNG failure")
+		}
+		results[addr]++
+	}
+	// 在按权重展开的集合上轮询，分布与权重完全一致
+	if results["s1"] != 300 || results["s2"] != 100 || results["s3"] != 0 {
+		t.Errorf("distribution = %v, want s1=300 s2=100", results)
+	}
+}
+
+func TestRandomWeightBalancer_CustomRNGFallback(t *testing.T) {
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "s1", Weight: 1},
+		{Addr: "s2", Weight: 1},
+	}, WithRandSource(failingSource{}), WithRNGFallback(NewRoundRobinBalancer([]string{"backup"})))
+
+	for i := 0; i < 3; i++ {
+		if got := balancer.Next(); got != "backup" {
+			t.Errorf("Next() = %v, want backup", got)
+		}
+	}
+}
+
+func TestWeightedPick_FailingSourceAndOverflow(t *testing.T) {
+	servers := []*Server{{Addr: "a", Weight: 1}, {Addr: "b", Weight: 2}}
+
+	// 随机源故障时不会 panic，在按权重展开的集合上轮询
+	var fb rngFallback
+	rng := rand.New(failingSource{})
+	want := []string{"a", "b", "b", "a"}
+	for i, w := range want {
+		if got := weightedPick(rng, &fb, servers, nil); got != w {
+			t.Fatalf("call %d: weightedPick() = %v, want %v", i, got, w)
+		}
+	}
+
+	// 总权重溢出时不选择
+	huge := []*Server{{Addr: "a", Weight: math.MaxInt64}, {Addr: "b", Weight: 1}}
+	if got := weightedPick(rand.New(rand.NewSource(1)), &fb, huge, nil); got != "" {
+		t.Errorf("weightedPick() = %v with overflowing total, want empty", got)
+	}
+}
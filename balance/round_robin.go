@@ -1,38 +1,199 @@
 package balance
 
 import (
-	"sync/atomic"
+	"math/rand"
+	"slices"
+	"sync"
 )
 
 type Balancer interface {
 	Next() string
 }
 
+// ServerLister 能列出自身服务器的负载均衡器，包装器（如健康检查）通过它拿到服务器列表
+type ServerLister interface {
+	Servers() []string
+}
+
 // RoundRobinBalancer
 // 简单、高效
-// 不能动态调整
+// 支持通过 AddServer/RemoveServer 动态调整，列表采用写时复制，Next 不需要加锁
 type RoundRobinBalancer struct {
-	servers []string
-	index   uint64
+	rr       *RoundRobin[string]
+	override DecisionOverride
+	onSelect func(addr string)
+	clock    Clock
+	ready    func(addr string) bool // 为 nil 时所有服务器都就绪，见 NewRoundRobinBalancerFunc
+	mu       sync.Mutex             // 串行化写操作
+}
+
+// NewRoundRobinBalancer 复制一份 servers，之后调用方修改原切片不会影响负载均衡器
+// servers 为空时不会 panic，Next 返回空字符串，NextE 返回 ErrEmptyPool
+// 起始位置是随机的，避免一批同时启动的进程都从第一个服务器开始，造成瞬时的不均衡
+func NewRoundRobinBalancer(servers []string, opts ...Option) Balancer {
+	o := newOptions(opts)
+	r := &RoundRobinBalancer{
+		rr:       NewRoundRobin(servers),
+		override: o.override,
+		onSelect: o.onSelect,
+		clock:    o.clock,
+	}
+	r.rr.index = uint64(rand.New(o.source).Int63())
+	return r
+}
+
+// NewRoundRobinBalancerFunc 每次选择时调用 ready 判断服务器是否就绪，健康状态由调用方维护
+// Next 跳过未就绪的服务器，一整圈都没有就绪的服务器时返回空字符串，NextE 返回 ErrAllUnavailable
+// 被跳过的位置照常消耗，就绪的服务器之间仍然按轮询顺序公平分配
+// 就绪判断也作用于 NextIndex、NextN、NextExcluding 和 Probabilities
+func NewRoundRobinBalancerFunc(servers []string, ready func(addr string) bool, opts ...Option) Balancer {
+	r := NewRoundRobinBalancer(servers, opts...).(*RoundRobinBalancer)
+	r.ready = ready
+	return r
+}
+
+// NewRoundRobinBalancerE 与 NewRoundRobinBalancer 相同，但 servers 为空时返回 ErrEmptyPool
+func NewRoundRobinBalancerE(servers []string, opts ...Option) (Balancer, error) {
+	if len(servers) == 0 {
+		return nil, ErrEmptyPool
+	}
+	return NewRoundRobinBalancer(servers, opts...), nil
 }
 
-func NewRoundRobinBalancer(servers []string) Balancer {
-	return &RoundRobinBalancer{
-		servers: servers,
+// NewRoundRobinBalancerWithSeed 起始位置由 seed 决定，相同种子的实例产生相同的序列，方便测试复现
+func NewRoundRobinBalancerWithSeed(servers []string, seed int64, opts ...Option) Balancer {
+	return NewRoundRobinBalancer(servers, slices.Concat(opts, []Option{WithRandSource(rand.NewSource(seed))})...)
+}
+
+// AddServer 在列表末尾追加服务器，已存在的地址不会重复添加
+func (r *RoundRobinBalancer) AddServer(addr string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	servers := r.rr.load()
+	if slices.Contains(servers, addr) {
+		return
+	}
+	r.rr.store(append(slices.Clip(servers), addr))
+}
+
+// RemoveServer 移除服务器，地址不存在时返回 false
+func (r *RoundRobinBalancer) RemoveServer(addr string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	servers := r.rr.load()
+	i := slices.Index(servers, addr)
+	if i < 0 {
+		return false
 	}
+	r.rr.store(slices.Delete(slices.Clone(servers), i, i+1))
+	return true
 }
 
 func (r *RoundRobinBalancer) Next() string {
-	if len(r.servers) == 0 {
+	addr, _ := r.NextE()
+	return addr
+}
+
+func (r *RoundRobinBalancer) NextE() (string, error) {
+	addr, err := r.next()
+	notifySelect(r.onSelect, addr, err)
+	return addr, err
+}
+
+// NextIndex 同时返回选中服务器的下标，方便调用方按位置维护连接、指标等并行数组
+// 下标对应选择时的服务器列表（即当时 Servers() 的结果），没有服务器时返回 (-1, "")
+// DecisionOverride 返回不在列表中的地址时没有对应的下标，此时忽略覆盖结果，按正常轮询选择
+func (r *RoundRobinBalancer) NextIndex() (int, string) {
+	i, addr, err := r.nextIndex(true)
+	notifySelect(r.onSelect, addr, err)
+	return i, addr
+}
+
+func (r *RoundRobinBalancer) next() (string, error) {
+	_, addr, err := r.nextIndex(false)
+	return addr, err
+}
+
+// nextIndex 只读取一次列表，并发的 AddServer/RemoveServer 不会让下标和地址错位
+// inList 为 true 时忽略不在列表中的覆盖结果；为 false 时原样返回（用来模拟错误路由），下标为 -1
+func (r *RoundRobinBalancer) nextIndex(inList bool) (int, string, error) {
+	servers := r.rr.load()
+	if len(servers) == 0 {
+		return -1, "", ErrEmptyPool
+	}
+	if addr, ok := applyOverride(r.override, func() []string {
+		return slices.DeleteFunc(slices.Clone(servers), func(addr string) bool { return !r.isReady(addr) })
+	}); ok {
+		if i := slices.Index(servers, addr); i >= 0 || !inList {
+			return i, addr, nil
+		}
+	}
+	// 最多检查一整圈
+	for k := 0; k < len(servers); k++ {
+		if i := r.rr.nextIndex(servers); r.isReady(servers[i]) {
+			return i, servers[i], nil
+		}
+	}
+	return -1, "", ErrAllUnavailable
+}
+
+func (r *RoundRobinBalancer) isReady(addr string) bool {
+	return r.ready == nil || r.ready(addr)
+}
+
+// NextN 按轮询顺序返回接下来的 n 个不同的服务器，适合对冲请求同时发往多个后端
+// 服务器不足 n 个时返回全部服务器，每个恰好一次；n 小于等于0或没有服务器时返回 nil
+// 设置了就绪判断时只返回就绪的服务器，最多检查一整圈，可能少于 n 个；DecisionOverride 不作用于 NextN
+func (r *RoundRobinBalancer) NextN(n int) []string {
+	servers := r.rr.load()
+	n = min(n, len(servers))
+	if n <= 0 {
+		return nil
+	}
+	var addrs []string
+	if r.ready == nil {
+		addrs = r.rr.nextN(servers, n)
+	} else {
+		// 与 Next 一样逐个推进，被跳过的位置照常消耗；并发推进时同一个服务器可能被轮到两次，只保留一次
+		for k := 0; k < len(servers) && len(addrs) < n; k++ {
+			if addr := servers[r.rr.nextIndex(servers)]; r.isReady(addr) && !slices.Contains(addrs, addr) {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	for _, addr := range addrs {
+		notifySelect(r.onSelect, addr, nil)
+	}
+	return addrs
+}
+
+// NextExcluding 跳过 exclude 中的地址，适合重试时避开刚失败的服务器；全部被排除（或未就绪）时返回空字符串
+// 被跳过的位置照常消耗，未被排除的服务器之间仍然按轮询顺序公平分配；DecisionOverride 不作用于 NextExcluding
+func (r *RoundRobinBalancer) NextExcluding(exclude ...string) string {
+	servers := r.rr.load()
+	excluded := func(addr string) bool {
+		return slices.Contains(exclude, addr) || !r.isReady(addr)
+	}
+	// 先确认有可选的服务器，全部被排除时不推进轮询位置
+	if !slices.ContainsFunc(servers, func(addr string) bool { return !excluded(addr) }) {
 		return ""
 	}
-	// 1. 原子递增索引值（保证并发安全）
-	// 注意：atomic.AddUint64 返回的是增加后的新值
-	newVal := atomic.AddUint64(&r.index, 1)
+	// 最多检查一整圈：就绪状态可能在检查之后变化
+	for k := 0; k < len(servers); k++ {
+		if addr := servers[r.rr.nextIndex(servers)]; !excluded(addr) {
+			notifySelect(r.onSelect, addr, nil)
+			return addr
+		}
+	}
+	return ""
+}
 
-	// 2. 对服务器列表长度取模，实现循环轮询
-	// 减 1 是因为我们想要从 0 开始计数，或者直接取模
-	idx := (newVal - 1) % uint64(len(r.servers))
+// Servers 返回服务器列表的副本
+func (r *RoundRobinBalancer) Servers() []string {
+	return r.rr.Items()
+}
 
-	return r.servers[idx]
+// Len 返回服务器数量
+func (r *RoundRobinBalancer) Len() int {
+	return r.rr.Len()
 }
@@ -1,30 +1,62 @@
 package balance
 
 import (
+	"maps"
+	"slices"
 	"sync"
 	"testing"
 )
 
+// newOrderedRoundRobin 从第一个服务器开始轮询，供依赖固定起点的测试使用
+func newOrderedRoundRobin(servers []string, opts ...Option) Balancer {
+	b := NewRoundRobinBalancer(servers, opts...).(*RoundRobinBalancer)
+	b.rr.index = 0
+	return b
+}
+
 func TestRoundRobinBalancer_Basic(t *testing.T) {
 	servers := []string{"server1", "server2", "server3"}
 	balancer := NewRoundRobinBalancer(servers)
 
-	tests := []struct {
-		index int
-		want  string
-	}{
-		{0, "server1"},
-		{1, "server2"},
-		{2, "server3"},
-		{3, "server1"}, // 循环回第一个
-		{4, "server2"},
-		{5, "server3"},
+	// 起始位置是随机的，之后按顺序循环
+	start := slices.Index(servers, balancer.Next())
+	if start < 0 {
+		t.Fatalf("first Next() returned an unknown server")
+	}
+	for i := 1; i < 6; i++ { // 第 3 次之后循环回起点
+		want := servers[(start+i)%len(servers)]
+		if got := balancer.Next(); got != want {
+			t.Errorf("Next() = %v, want %v", got, want)
+		}
 	}
+}
 
-	for _, tt := range tests {
-		got := balancer.Next()
-		if got != tt.want {
-			t.Errorf("Next() = %v, want %v", got, tt.want)
+func TestRoundRobinBalancer_SeededStart(t *testing.T) {
+	servers := []string{"s0", "s1", "s2", "s3", "s4", "s5", "s6"}
+	sequence := func(b Balancer) []string {
+		seq := make([]string, len(servers))
+		for i := range seq {
+			seq[i] = b.Next()
+		}
+		return seq
+	}
+
+	// 相同种子的序列完全一致
+	seq1 := sequence(NewRoundRobinBalancerWithSeed(servers, 1))
+	if again := sequence(NewRoundRobinBalancerWithSeed(servers, 1)); !slices.Equal(seq1, again) {
+		t.Errorf("same seed: %v != %v", seq1, again)
+	}
+
+	// 不同种子的序列是同一个环的不同旋转
+	seq2 := sequence(NewRoundRobinBalancerWithSeed(servers, 2))
+	if seq1[0] == seq2[0] {
+		t.Errorf("seeds 1 and 2 start at the same server %v", seq1[0])
+	}
+	for _, seq := range [][]string{seq1, seq2} {
+		start := slices.Index(servers, seq[0])
+		rotated := append(slices.Clone(servers[start:]), servers[:start]...)
+		if !slices.Equal(seq, rotated) {
+			t.Errorf("sequence %v is not a rotation of %v", seq, servers)
 		}
 	}
 }
@@ -119,3 +151,311 @@ func BenchmarkRoundRobinBalancer_Next(b *testing.B) {
 		}
 	})
 }
+
+func TestRoundRobinBalancer_AddRemoveServer(t *testing.T) {
+	balancer := NewRoundRobinBalancer([]string{"server1", "server2"}).(*RoundRobinBalancer)
+
+	balancer.AddServer("server3")
+	balancer.AddServer("server3") // 重复添加被忽略
+	seen := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		seen[balancer.Next()]++
+	}
+	if len(seen) != 3 || seen["server3"] != 10 {
+		t.Errorf("after AddServer distribution = %v, want 10 each", seen)
+	}
+
+	if !balancer.RemoveServer("server1") {
+		t.Error("RemoveServer(server1) = false, want true")
+	}
+	if balancer.RemoveServer("server1") {
+		t.Error("RemoveServer(server1) again = true, want false")
+	}
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(); got == "server1" {
+			t.Fatalf("Next() returned removed server %v", got)
+		}
+	}
+
+	balancer.RemoveServer("server2")
+	balancer.RemoveServer("server3")
+	if _, err := balancer.NextE(); err != ErrEmptyPool {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+}
+
+func TestRoundRobinBalancer_ConcurrentRemove(t *testing.T) {
+	balancer := NewRoundRobinBalancer([]string{"s0", "s1", "s2", "s3", "s4"}).(*RoundRobinBalancer)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					balancer.Next()
+				}
+			}
+		}()
+	}
+
+	// 反复缩小、扩大列表，Next 不应越界 panic
+	for i := 0; i < 1000; i++ {
+		balancer.RemoveServer("s4")
+		balancer.RemoveServer("s3")
+		balancer.AddServer("s3")
+		balancer.AddServer("s4")
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRoundRobinBalancer_CopiesServers(t *testing.T) {
+	servers := []string{"server1", "server2"}
+	balancer := NewRoundRobinBalancer(servers)
+	servers[0] = "mutated"
+
+	for i := 0; i < 2; i++ {
+		if got := balancer.Next(); got == "mutated" {
+			t.Errorf("Next() = %v, caller mutation must not leak in", got)
+		}
+	}
+}
+
+func TestRoundRobinBalancer_Empty(t *testing.T) {
+	balancer := NewRoundRobinBalancer(nil)
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty string", got)
+	}
+}
+
+func TestServersAndLen(t *testing.T) {
+	type introspector interface {
+		ServerLister
+		Len() int
+	}
+	balancers := map[string]Balancer{
+		"round_robin":   NewRoundRobinBalancer([]string{"s1", "s2", "s3"}),
+		"random":        NewRandomBalancer([]string{"s1", "s2", "s3"}),
+		"random_weight": NewRandomWeightBalancer([]*Server{{Addr: "s1", Weight: 1}, {Addr: "s2", Weight: 0}, {Addr: "s3", Weight: 5}}),
+	}
+	for name, b := range balancers {
+		in := b.(introspector)
+		if in.Len() != 3 {
+			t.Errorf("%s: Len() = %d, want 3", name, in.Len())
+		}
+		servers := in.Servers()
+		if len(servers) != 3 || servers[0] != "s1" || servers[2] != "s3" {
+			t.Errorf("%s: Servers() = %v, want [s1 s2 s3]", name, servers)
+		}
+
+		// 修改返回的切片不影响负载均衡器
+		servers[0] = "mutated"
+		if got := in.Servers()[0]; got != "s1" {
+			t.Errorf("%s: Servers()[0] = %v after mutation, want s1", name, got)
+		}
+		for i := 0; i < 20; i++ {
+			if got := b.Next(); got == "mutated" {
+				t.Fatalf("%s: Next() returned mutated address", name)
+			}
+		}
+	}
+}
+
+func TestRoundRobinBalancer_NextIndex(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	balancer := newOrderedRoundRobin(servers).(*RoundRobinBalancer)
+
+	for i := 0; i < 6; i++ {
+		idx, addr := balancer.NextIndex()
+		if idx != i%3 || addr != servers[idx] {
+			t.Errorf("NextIndex() = %d, %v, want %d, %v", idx, addr, i%3, servers[i%3])
+		}
+	}
+
+	empty := NewRoundRobinBalancer(nil).(*RoundRobinBalancer)
+	if idx, addr := empty.NextIndex(); idx != -1 || addr != "" {
+		t.Errorf("NextIndex() on empty = %d, %q, want -1, \"\"", idx, addr)
+	}
+}
+
+func TestRoundRobinBalancer_NextIndexConcurrent(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := NewRoundRobinBalancer(servers).(*RoundRobinBalancer)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				idx, addr := balancer.NextIndex()
+				if idx < 0 || idx >= len(servers) || servers[idx] != addr {
+					t.Errorf("NextIndex() = %d, %v out of bounds or mismatched", idx, addr)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRoundRobinBalancer_NextN(t *testing.T) {
+	servers := []string{"s1", "s2", "s3", "s4"}
+	balancer := newOrderedRoundRobin(servers).(*RoundRobinBalancer)
+
+	if got := balancer.NextN(2); !slices.Equal(got, []string{"s1", "s2"}) {
+		t.Errorf("NextN(2) = %v, want [s1 s2]", got)
+	}
+	// 跨越列表末尾时继续轮询
+	if got := balancer.NextN(3); !slices.Equal(got, []string{"s3", "s4", "s1"}) {
+		t.Errorf("NextN(3) = %v, want [s3 s4 s1]", got)
+	}
+	if got := balancer.Next(); got != "s2" {
+		t.Errorf("Next() after NextN = %v, want s2", got)
+	}
+
+	// n 超过服务器数量时每个服务器恰好返回一次
+	got := balancer.NextN(10)
+	if len(got) != len(servers) {
+		t.Fatalf("NextN(10) = %v, want all %d servers", got, len(servers))
+	}
+	sorted := slices.Sorted(slices.Values(got))
+	if !slices.Equal(sorted, servers) {
+		t.Errorf("NextN(10) = %v, want each server exactly once", got)
+	}
+
+	if got := balancer.NextN(0); got != nil {
+		t.Errorf("NextN(0) = %v, want nil", got)
+	}
+	if got := NewRoundRobinBalancer(nil).(*RoundRobinBalancer).NextN(2); got != nil {
+		t.Errorf("NextN() on empty = %v, want nil", got)
+	}
+}
+
+func TestRoundRobinBalancer_NextExcluding(t *testing.T) {
+	balancer := newOrderedRoundRobin([]string{"s1", "s2", "s3"}).(*RoundRobinBalancer)
+
+	// 跳过 s2，其余服务器仍然轮流被选中
+	want := []string{"s1", "s3", "s1", "s3"}
+	for _, w := range want {
+		if got := balancer.NextExcluding("s2"); got != w {
+			t.Errorf("NextExcluding(s2) = %v, want %v", got, w)
+		}
+	}
+
+	// 全部排除时返回空字符串，且不推进轮询位置
+	if got := balancer.NextExcluding("s1", "s2", "s3"); got != "" {
+		t.Errorf("NextExcluding(all) = %v, want empty string", got)
+	}
+	if got := balancer.Next(); got != "s1" {
+		t.Errorf("Next() after NextExcluding(all) = %v, want s1", got)
+	}
+	if got := balancer.NextExcluding(); got != "s2" {
+		t.Errorf("NextExcluding() = %v, want s2", got)
+	}
+}
+
+func TestRoundRobinBalancerFunc_TracksReadiness(t *testing.T) {
+	var mu sync.Mutex
+	notReady := map[string]bool{"s2": true}
+	ready := func(addr string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return !notReady[addr]
+	}
+	balancer := NewRoundRobinBalancerFunc([]string{"s1", "s2", "s3"}, ready).(*RoundRobinBalancer)
+	balancer.rr.index = 0
+
+	// s2 未就绪时 s1 和 s3 交替，分配均匀
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		counts[balancer.Next()]++
+	}
+	if counts["s2"] != 0 || counts["s1"] != 50 || counts["s3"] != 50 {
+		t.Errorf("counts = %v, want s1=50 s3=50", counts)
+	}
+
+	// 恢复后重新参与轮询
+	mu.Lock()
+	delete(notReady, "s2")
+	mu.Unlock()
+	counts = make(map[string]int)
+	for i := 0; i < 99; i++ {
+		counts[balancer.Next()]++
+	}
+	if counts["s1"] != 33 || counts["s2"] != 33 || counts["s3"] != 33 {
+		t.Errorf("counts = %v, want 33 each after s2 is ready", counts)
+	}
+
+	// 全部未就绪时返回空
+	mu.Lock()
+	notReady = map[string]bool{"s1": true, "s2": true, "s3": true}
+	mu.Unlock()
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty string when nothing is ready", got)
+	}
+	if _, err := balancer.NextE(); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+	if got := balancer.NextExcluding(); got != "" {
+		t.Errorf("NextExcluding() = %v, want empty string when nothing is ready", got)
+	}
+}
+
+func TestRoundRobinBalancerFunc_NextExcludingReadinessFlips(t *testing.T) {
+	// 第一次判断时就绪，之后立刻变为未就绪，NextExcluding 不能一直循环
+	var calls int
+	ready := func(addr string) bool {
+		calls++
+		return calls == 1
+	}
+	balancer := NewRoundRobinBalancerFunc([]string{"s1", "s2"}, ready).(*RoundRobinBalancer)
+	if got := balancer.NextExcluding(); got != "" {
+		t.Errorf("NextExcluding() = %v, want empty string", got)
+	}
+	if calls > 3 {
+		t.Errorf("ready called %d times, want at most one check plus one cycle", calls)
+	}
+}
+
+func TestRoundRobinBalancerFunc_NextNAndProbabilities(t *testing.T) {
+	ready := func(addr string) bool { return addr != "s2" }
+	balancer := NewRoundRobinBalancerFunc([]string{"s1", "s2", "s3", "s4"}, ready).(*RoundRobinBalancer)
+	balancer.rr.index = 0
+
+	// 跳过未就绪的 s2，服务器不足时只返回就绪的
+	if got, want := balancer.NextN(2), []string{"s1", "s3"}; !slices.Equal(got, want) {
+		t.Errorf("NextN(2) = %v, want %v", got, want)
+	}
+	if got := balancer.NextN(4); len(got) != 3 || slices.Contains(got, "s2") {
+		t.Errorf("NextN(4) = %v, want the three ready servers", got)
+	}
+
+	// 就绪服务器之间 1/N，未就绪的为0
+	probs := balancer.Probabilities()
+	want := map[string]float64{"s1": 1.0 / 3, "s2": 0, "s3": 1.0 / 3, "s4": 1.0 / 3}
+	if !maps.Equal(probs, want) {
+		t.Errorf("Probabilities() = %v, want %v", probs, want)
+	}
+}
+
+func TestRoundRobinBalancer_NextIndexOverrideOutsideList(t *testing.T) {
+	balancer := NewRoundRobinBalancer([]string{"s1", "s2"}, WithDecisionOverride(func([]string) (string, bool) {
+		return "elsewhere", true
+	})).(*RoundRobinBalancer)
+
+	// NextIndex 不能返回没有下标的地址，改为正常轮询
+	if i, addr := balancer.NextIndex(); i < 0 || addr != balancer.Servers()[i] {
+		t.Errorf("NextIndex() = %d, %q, want a valid index", i, addr)
+	}
+	// Next 仍然按覆盖结果模拟错误路由
+	if got := balancer.Next(); got != "elsewhere" {
+		t.Errorf("Next() = %v, want elsewhere", got)
+	}
+}
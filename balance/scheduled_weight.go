@@ -0,0 +1,92 @@
+package balance
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HintBalancer 在选择的同时给出该选择预计稳定到什么时候，便于调用方设置缓存 TTL
+// stableUntil 为零值表示没有已知的时间边界（只会因成员变化而改变）
+type HintBalancer interface {
+	NextWithHint() (addr string, stableUntil time.Time)
+}
+
+// WeightPhase 从 At 开始生效的一组权重
+type WeightPhase struct {
+	At      time.Time
+	Servers []*Server
+}
+
+// ScheduledWeightBalancer
+// 按时间表切换权重的加权随机，比如白天/夜间使用不同的权重
+// 第一个阶段开始之前，使用第一个阶段的权重
+type ScheduledWeightBalancer struct {
+	phases   []WeightPhase
+	clock    Clock
+	rng      *rand.Rand
+	fallback rngFallback
+	lock     sync.Mutex
+}
+
+// NewScheduledWeightBalancer 支持 WithClock 和 WithRandSource
+// 每个阶段的权重按与 NewRandomWeightBalancerE 相同的规则校验；Servers 会被深拷贝，之后修改传入的切片不影响选择
+func NewScheduledWeightBalancer(phases []WeightPhase, opts ...Option) (*ScheduledWeightBalancer, error) {
+	o := newOptions(opts)
+	sorted := make([]WeightPhase, 0, len(phases))
+	for i, phase := range phases {
+		if err := validateWeights(phase.Servers, maxWeight, maxTotalWeight); err != nil {
+			return nil, fmt.Errorf("phase %d: %w", i, err)
+		}
+		sorted = append(sorted, WeightPhase{At: phase.At, Servers: cloneServers(phase.Servers)})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At.Before(sorted[j].At)
+	})
+	return &ScheduledWeightBalancer{
+		phases: sorted,
+		clock:  o.clock,
+		rng:    rand.New(o.source),
+	}, nil
+}
+
+func (s *ScheduledWeightBalancer) Next() string {
+	addr, _ := s.NextWithHint()
+	return addr
+}
+
+// NextWithHint 返回的 stableUntil 是下一个阶段的开始时间
+func (s *ScheduledWeightBalancer) NextWithHint() (string, time.Time) {
+	if len(s.phases) == 0 {
+		return "", time.Time{}
+	}
+
+	now := s.clock.Now()
+	// 找到最后一个已经开始的阶段
+	i := sort.Search(len(s.phases), func(i int) bool {
+		return s.phases[i].At.After(now)
+	})
+	current := max(i-1, 0)
+
+	var stableUntil time.Time
+	if i < len(s.phases) {
+		stableUntil = s.phases[i].At
+	}
+
+	s.lock.Lock()
+	addr := weightedPick(s.rng, &s.fallback, s.phases[current].Servers, nil)
+	s.lock.Unlock()
+	return addr, stableUntil
+}
+
+// NextWithHint 轮询每次调用都会变化，stableUntil 为当前时间，时间来源见 WithClock
+func (r *RoundRobinBalancer) NextWithHint() (string, time.Time) {
+	return r.Next(), r.clock.Now()
+}
+
+// NextWithHint 随机每次调用都会变化，stableUntil 为当前时间，时间来源见 WithClock
+func (r *RandomBalancer) NextWithHint() (string, time.Time) {
+	return r.Next(), r.clock.Now()
+}
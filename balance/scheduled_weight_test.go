@@ -0,0 +1,103 @@
+package balance
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduledWeightBalancer_Hint(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	day := start.Add(8 * time.Hour)
+	night := start.Add(20 * time.Hour)
+
+	balancer, err := NewScheduledWeightBalancer([]WeightPhase{
+		{At: night, Servers: []*Server{{Addr: "night", Weight: 1}}},
+		{At: day, Servers: []*Server{{Addr: "day", Weight: 1}}},
+	}, WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewScheduledWeightBalancer() error = %v", err)
+	}
+
+	// 第一个阶段开始前使用第一个阶段的权重
+	addr, until := balancer.NextWithHint()
+	if addr != "day" || !until.Equal(day) {
+		t.Errorf("before schedule: got %v, %v, want day, %v", addr, until, day)
+	}
+
+	clock.Advance(9 * time.Hour)
+	addr, until = balancer.NextWithHint()
+	if addr != "day" {
+		t.Errorf("Next() = %v, want day", addr)
+	}
+	if until.After(night) || !until.After(clock.Now()) {
+		t.Errorf("stableUntil = %v, want before next boundary %v", until, night)
+	}
+
+	clock.Advance(12 * time.Hour)
+	addr, until = balancer.NextWithHint()
+	if addr != "night" || !until.IsZero() {
+		t.Errorf("last phase: got %v, %v, want night, zero time", addr, until)
+	}
+}
+
+func TestScheduledWeightBalancer_ValidatesAndCopies(t *testing.T) {
+	_, err := NewScheduledWeightBalancer([]WeightPhase{
+		{Servers: []*Server{{Addr: "a", Weight: 1}}},
+		{Servers: []*Server{{Addr: "b", Weight: -1}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "phase 1") {
+		t.Errorf("error = %v, want error naming phase 1", err)
+	}
+
+	servers := []*Server{{Addr: "a", Weight: 1}}
+	balancer, err := NewScheduledWeightBalancer([]WeightPhase{{Servers: servers}})
+	if err != nil {
+		t.Fatalf("NewScheduledWeightBalancer() error = %v", err)
+	}
+	// 构造之后修改传入的切片和 Server，不影响选择
+	servers[0].Addr = "changed"
+	servers[0].Weight = maxWeight + 1
+	if got := balancer.Next(); got != "a" {
+		t.Errorf("Next() = %v, want a", got)
+	}
+}
+
+func TestNextWithHint_Immediate(t *testing.T) {
+	before := time.Now()
+	balancers := []HintBalancer{
+		NewRoundRobinBalancer([]string{"a", "b"}).(HintBalancer),
+		NewRandomBalancer([]string{"a", "b"}).(HintBalancer),
+	}
+
+	for _, b := range balancers {
+		addr, until := b.NextWithHint()
+		if addr == "" {
+			t.Errorf("expected non-empty address")
+		}
+		if until.Before(before) || until.After(time.Now()) {
+			t.Errorf("stableUntil = %v, want now", until)
+		}
+	}
+}
+
+func TestNextWithHint_FakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	balancers := []HintBalancer{
+		NewRoundRobinBalancer([]string{"a", "b"}, WithClock(clock)).(HintBalancer),
+		NewRandomBalancer([]string{"a", "b"}, WithClock(clock)).(HintBalancer),
+	}
+
+	for _, b := range balancers {
+		start := clock.Now()
+		if _, until := b.NextWithHint(); !until.Equal(start) {
+			t.Errorf("stableUntil = %v, want fake now %v", until, start)
+		}
+		// 推进假时钟立即生效，不需要真的等待
+		clock.Advance(time.Hour)
+		if _, until := b.NextWithHint(); !until.Equal(start.Add(time.Hour)) {
+			t.Errorf("stableUntil = %v, want %v after Advance", until, start.Add(time.Hour))
+		}
+	}
+}
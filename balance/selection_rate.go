@@ -0,0 +1,77 @@
+package balance
+
+import (
+	"sync"
+	"time"
+)
+
+// rateBucket 一秒内各服务器的选择次数
+type rateBucket struct {
+	second int64
+	counts map[string]int
+}
+
+// SelectionRateBalancer
+// 记录每个服务器最近一段时间的选择速率（次/秒），可作为自动扩缩容的信号
+// 使用按秒分桶的环形缓冲，内存占用只和 maxWindow 以及服务器数量有关
+type SelectionRateBalancer struct {
+	balancer Balancer
+	clock    Clock
+	buckets  []rateBucket
+	lock     sync.Mutex
+}
+
+// NewSelectionRateBalancer maxWindow 是 SelectionRate 支持的最大窗口，按秒向上取整
+// 支持 WithClock
+func NewSelectionRateBalancer(b Balancer, maxWindow time.Duration, opts ...Option) *SelectionRateBalancer {
+	o := newOptions(opts)
+	n := int((maxWindow + time.Second - 1) / time.Second)
+	return &SelectionRateBalancer{
+		balancer: b,
+		clock:    o.clock,
+		buckets:  make([]rateBucket, max(n, 1)),
+	}
+}
+
+func (s *SelectionRateBalancer) Next() string {
+	addr := s.balancer.Next()
+	if addr == "" {
+		return addr
+	}
+
+	sec := s.clock.Now().Unix()
+	s.lock.Lock()
+	b := &s.buckets[sec%int64(len(s.buckets))]
+	if b.second != sec || b.counts == nil {
+		// 桶已过期，复用它记录当前这一秒
+		b.second = sec
+		b.counts = make(map[string]int)
+	}
+	b.counts[addr]++
+	s.lock.Unlock()
+	return addr
+}
+
+// SelectionRate 返回最近 window 内每个服务器的平均选择速率，window 超过 maxWindow 时按 maxWindow 计算
+func (s *SelectionRateBalancer) SelectionRate(window time.Duration) map[string]float64 {
+	seconds := int64((window + time.Second - 1) / time.Second)
+	seconds = min(max(seconds, 1), int64(len(s.buckets)))
+	now := s.clock.Now().Unix()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rates := make(map[string]float64)
+	for _, b := range s.buckets {
+		if b.counts == nil || b.second <= now-seconds || b.second > now {
+			continue
+		}
+		for addr, c := range b.counts {
+			rates[addr] += float64(c)
+		}
+	}
+	for addr := range rates {
+		rates[addr] /= float64(seconds)
+	}
+	return rates
+}
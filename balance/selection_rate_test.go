@@ -0,0 +1,57 @@
+package balance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSelectionRateBalancer_SteadyAndDecay(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewSelectionRateBalancer(NewRoundRobinBalancer([]string{"a", "b"}), time.Minute, WithClock(clock))
+
+	// 每秒 20 次选择，轮询下每个服务器 10 次/秒
+	for sec := 0; sec < 30; sec++ {
+		for i := 0; i < 20; i++ {
+			balancer.Next()
+		}
+		clock.Advance(time.Second)
+	}
+
+	rates := balancer.SelectionRate(10 * time.Second)
+	for _, addr := range []string{"a", "b"} {
+		if math.Abs(rates[addr]-10) > 1.1 {
+			t.Errorf("%s: rate = %.2f, want ~10", addr, rates[addr])
+		}
+	}
+
+	// 停止选择后速率逐渐下降，直到为0
+	clock.Advance(5 * time.Second)
+	decayed := balancer.SelectionRate(10 * time.Second)
+	if decayed["a"] >= rates["a"] || decayed["a"] == 0 {
+		t.Errorf("rate should decay: before %.2f, after %.2f", rates["a"], decayed["a"])
+	}
+
+	clock.Advance(10 * time.Second)
+	if got := balancer.SelectionRate(10 * time.Second); len(got) != 0 {
+		t.Errorf("rate should be empty after window passes, got %v", got)
+	}
+}
+
+func TestSelectionRateBalancer_BoundedWindow(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	balancer := NewSelectionRateBalancer(NewRoundRobinBalancer([]string{"a"}), 5*time.Second, WithClock(clock))
+
+	for sec := 0; sec < 100; sec++ {
+		balancer.Next()
+		clock.Advance(time.Second)
+	}
+
+	if len(balancer.buckets) != 5 {
+		t.Errorf("buckets = %d, want 5", len(balancer.buckets))
+	}
+	// 超过最大窗口时按最大窗口计算
+	if got := balancer.SelectionRate(time.Hour)["a"]; math.Abs(got-0.8) > 0.01 {
+		t.Errorf("rate = %.2f, want 0.8", got)
+	}
+}
@@ -0,0 +1,86 @@
+package balance
+
+import (
+	"sync"
+)
+
+// SessionStickyBalancer
+// 基于会话 key 的粘性负载均衡：key 第一次请求时选择一个服务器并绑定，之后一直路由到它
+// 服务器被摘除时，绑定在它上面的会话会整体迁移到新选出的服务器，而不是直接断开
+// 绑定不会自动过期，每个 key 占用一条记录；会话结束时调用 Forget 释放，否则内存随 key 的数量一直增长
+type SessionStickyBalancer struct {
+	health   *HealthBalancer
+	sessions map[string]string
+	lock     sync.Mutex
+}
+
+func NewSessionStickyBalancer(b Balancer, servers []string) *SessionStickyBalancer {
+	return &SessionStickyBalancer{
+		health:   NewHealthBalancer(b, servers),
+		sessions: make(map[string]string),
+	}
+}
+
+// NextForKey 返回 key 绑定的服务器；未绑定或绑定的服务器不可用时，重新选择并绑定
+func (s *SessionStickyBalancer) NextForKey(key string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if addr, ok := s.sessions[key]; ok && s.health.IsAvailable(addr) {
+		return addr
+	}
+	addr := s.health.Next()
+	if addr != "" {
+		s.sessions[key] = addr
+	}
+	return addr
+}
+
+// Lookup 返回 key 当前绑定的服务器，不会触发选择
+func (s *SessionStickyBalancer) Lookup(key string) (string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	addr, ok := s.sessions[key]
+	return addr, ok
+}
+
+// Forget 解除 key 的绑定，key 不存在时什么也不做；之后再请求该 key 会重新选择
+func (s *SessionStickyBalancer) Forget(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.sessions, key)
+}
+
+// MigrateSessions 把绑定在 from 上的所有会话改绑到 to，返回迁移的会话数
+func (s *SessionStickyBalancer) MigrateSessions(from, to string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.migrateLocked(from, to)
+}
+
+// Drain 摘除服务器，并把它的会话迁移到新选出的服务器
+// 没有其它可用服务器时只摘除，会话保持原样，等下次请求时再重新选择
+func (s *SessionStickyBalancer) Drain(addr string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.health.Drain(addr)
+	if to := s.health.Next(); to != "" {
+		s.migrateLocked(addr, to)
+	}
+}
+
+func (s *SessionStickyBalancer) Undrain(addr string) {
+	s.health.Undrain(addr)
+}
+
+func (s *SessionStickyBalancer) migrateLocked(from, to string) int {
+	n := 0
+	for key, addr := range s.sessions {
+		if addr == from {
+			s.sessions[key] = to
+			n++
+		}
+	}
+	return n
+}
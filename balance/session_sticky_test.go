@@ -0,0 +1,89 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSessionStickyBalancer_Sticky(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	balancer := NewSessionStickyBalancer(NewRoundRobinBalancer(servers), servers)
+
+	first := balancer.NextForKey("user-1")
+	for i := 0; i < 10; i++ {
+		if got := balancer.NextForKey("user-1"); got != first {
+			t.Fatalf("NextForKey() = %v, want sticky %v", got, first)
+		}
+	}
+}
+
+func TestSessionStickyBalancer_DrainMigrates(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	balancer := NewSessionStickyBalancer(NewRoundRobinBalancer(servers), servers)
+
+	bound := make(map[string]string)
+	for i := 0; i < 9; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		bound[key] = balancer.NextForKey(key)
+	}
+
+	balancer.Drain("s1")
+
+	// s1 上的会话全部迁移到同一个新服务器，其它会话不受影响
+	var target string
+	for key, old := range bound {
+		got, _ := balancer.Lookup(key)
+		if old != "s1" {
+			if got != old {
+				t.Errorf("%s: moved from %s to %s", key, old, got)
+			}
+			continue
+		}
+		if got == "s1" {
+			t.Errorf("%s: still bound to drained server", key)
+		}
+		if target == "" {
+			target = got
+		} else if got != target {
+			t.Errorf("%s: migrated to %s, want consistent %s", key, got, target)
+		}
+		if next := balancer.NextForKey(key); next != got {
+			t.Errorf("%s: NextForKey() = %s, want migrated %s", key, next, got)
+		}
+	}
+}
+
+func TestSessionStickyBalancer_MigrateSessions(t *testing.T) {
+	servers := []string{"s1", "s2"}
+	balancer := NewSessionStickyBalancer(newOrderedRoundRobin(servers), servers)
+	balancer.NextForKey("a") // s1
+	balancer.NextForKey("b") // s2
+	balancer.NextForKey("c") // s1
+
+	if n := balancer.MigrateSessions("s1", "s2"); n != 2 {
+		t.Errorf("MigrateSessions() = %d, want 2", n)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if got := balancer.NextForKey(key); got != "s2" {
+			t.Errorf("%s: NextForKey() = %v, want s2", key, got)
+		}
+	}
+}
+
+func TestSessionStickyBalancer_Forget(t *testing.T) {
+	servers := []string{"s1", "s2"}
+	balancer := NewSessionStickyBalancer(newOrderedRoundRobin(servers), servers)
+
+	if got := balancer.NextForKey("user-1"); got != "s1" {
+		t.Fatalf("NextForKey() = %v, want s1", got)
+	}
+	balancer.Forget("user-1")
+	balancer.Forget("unknown")
+	if _, ok := balancer.Lookup("user-1"); ok {
+		t.Error("Lookup() found user-1 after Forget")
+	}
+	// 解除绑定后重新选择
+	if got := balancer.NextForKey("user-1"); got != "s2" {
+		t.Errorf("NextForKey() = %v, want s2 after Forget", got)
+	}
+}
@@ -0,0 +1,53 @@
+package balance
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ShadowBalancer
+// 影子流量：Next 始终返回主池的选择，同时按 fraction 的比例从影子池选一个服务器，通过回调通知调用方
+// 影子池的选择不会影响主池的结果
+type ShadowBalancer struct {
+	primary  Balancer
+	shadow   Balancer
+	fraction float64
+	onShadow func(primary, shadow string)
+	rng      *rand.Rand
+	mu       sync.Mutex
+}
+
+// NewShadowBalancer fraction 会被限制在 [0, 1] 之间
+func NewShadowBalancer(primary Balancer, shadow Balancer, fraction float64) *ShadowBalancer {
+	return &ShadowBalancer{
+		primary:  primary,
+		shadow:   shadow,
+		fraction: min(max(fraction, 0), 1),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// OnShadow 设置影子回调，参数为本次主池的选择和影子池的选择
+func (s *ShadowBalancer) OnShadow(fn func(primary, shadow string)) {
+	s.mu.Lock()
+	s.onShadow = fn
+	s.mu.Unlock()
+}
+
+func (s *ShadowBalancer) Next() string {
+	addr := s.primary.Next()
+
+	s.mu.Lock()
+	fn := s.onShadow
+	hit := fn != nil && s.rng.Float64() < s.fraction
+	s.mu.Unlock()
+
+	// 回调在锁外执行，避免回调里再调用 balancer 时死锁
+	if hit {
+		if shadowAddr := s.shadow.Next(); shadowAddr != "" {
+			fn(addr, shadowAddr)
+		}
+	}
+	return addr
+}
@@ -0,0 +1,60 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestShadowBalancer_PrimaryUnchanged(t *testing.T) {
+	servers := []string{"p1", "p2", "p3"}
+	plain := NewRoundRobinBalancerWithSeed(servers, 1)
+	balancer := NewShadowBalancer(NewRoundRobinBalancerWithSeed(servers, 1), NewRoundRobinBalancer([]string{"s1"}), 0.5)
+	balancer.OnShadow(func(primary, shadow string) {})
+
+	for i := 0; i < 300; i++ {
+		want := plain.Next()
+		if got := balancer.Next(); got != want {
+			t.Fatalf("iteration %d: Next() = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestShadowBalancer_FractionRate(t *testing.T) {
+	balancer := NewShadowBalancer(
+		NewRoundRobinBalancer([]string{"p1"}),
+		NewRoundRobinBalancer([]string{"s1", "s2"}),
+		0.2,
+	)
+
+	shadowed := 0
+	balancer.OnShadow(func(primary, shadow string) {
+		if primary != "p1" {
+			t.Errorf("primary = %v, want p1", primary)
+		}
+		if shadow != "s1" && shadow != "s2" {
+			t.Errorf("unexpected shadow %v", shadow)
+		}
+		shadowed++
+	})
+
+	iterations := 10000
+	for i := 0; i < iterations; i++ {
+		balancer.Next()
+	}
+
+	rate := float64(shadowed) / float64(iterations)
+	t.Logf("Shadow rate: %.3f", rate)
+	if rate < 0.18 || rate > 0.22 {
+		t.Errorf("Expected shadow rate around 0.2, got %.3f", rate)
+	}
+}
+
+func TestShadowBalancer_ZeroFraction(t *testing.T) {
+	balancer := NewShadowBalancer(NewRoundRobinBalancer([]string{"p1"}), NewRoundRobinBalancer([]string{"s1"}), 0)
+	balancer.OnShadow(func(primary, shadow string) {
+		t.Errorf("callback should not fire with zero fraction")
+	})
+
+	for i := 0; i < 100; i++ {
+		balancer.Next()
+	}
+}
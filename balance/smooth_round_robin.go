@@ -3,7 +3,10 @@ package balance
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -13,17 +16,135 @@ const (
 
 type Node struct {
 	server  string
-	current int // 当前权重
-	weight  int // 权重
+	current int64 // 当前权重
+	weight  int   // 权重
+	drained bool  // 摘除后有效权重为0，不参与选择
+}
+
+// NewNode 创建一个节点，weight 在构造负载均衡器时校验
+func NewNode(server string, weight int) *Node {
+	return &Node{
+		server: server,
+		weight: weight,
+	}
+}
+
+// Server 节点地址，构造后不再修改（ReplaceAddr 会换成新节点），可以随时读取
+func (n *Node) Server() string {
+	return n.server
+}
+
+// Weight 节点权重，读取时不加锁
+// 节点交给负载均衡器之后，与 UpdateWeight 并发读取是数据竞争；需要并发读取时使用 CurrentWeights 等快照方法
+func (n *Node) Weight() int {
+	return n.weight
+}
+
+// NodeSnapshot 节点在某一时刻的只读副本，WithLess 的比较函数通过它读取当前权重，不直接访问存活的节点
+type NodeSnapshot struct {
+	Server  string
+	Weight  int
+	Current int64 // 当前权重
+}
+
+// snapshot 调用方持有锁
+func (n *Node) snapshot() NodeSnapshot {
+	return NodeSnapshot{Server: n.server, Weight: n.weight, Current: n.current}
 }
 
 type SmoothBalancer interface {
+	// Next ctx 已经取消时返回 nil
 	Next(ctx context.Context) *Node
+	// NextE ctx 已经取消时返回 ctx.Err()，不会选择节点
+	NextE(ctx context.Context) (*Node, error)
+	// ReplaceAddr 用新地址的节点替换旧节点，保留权重和当前权重
+	// 调用方持有的旧 *Node 不会被修改，之后也不再参与选择
+	ReplaceAddr(old, new string) error
+	// UpdateWeight 调整节点权重，并重置所有节点的当前权重，让分布按新权重重新收敛
+	UpdateWeight(server string, weight int) error
+	// Snapshot 返回每个节点的当前权重，用于进程重启后通过 Restore 恢复分布状态
+	Snapshot() map[string]int
+	// CurrentWeights 返回每个节点当前权重的快照，用于排查分布问题
+	CurrentWeights() map[string]int
+	// Restore 恢复 Snapshot 保存的当前权重，包含未知节点时返回错误且不做任何修改
+	Restore(state map[string]int) error
+	// Drain 摘除节点：不再被选中，但保留权重，之后可以 Undrain 恢复；全部摘除时 Next 返回 nil
+	Drain(server string) error
+	// Undrain 恢复被摘除的节点
+	Undrain(server string) error
+	// RemoveServer 移除节点，不存在时返回 false；全部移除后 Next 返回 nil，NextE 返回 ErrEmptyPool
+	RemoveServer(server string) bool
 }
 
 type smoothRoundRobinBalancer struct {
-	nodes []*Node
-	lock  sync.RWMutex
+	nodes     []*Node
+	less      func(a, b *Node) bool
+	timing    TimingSink
+	tracer    func(ctx context.Context, selected string)
+	maxWeight int64
+	maxTotal  int64
+	lock      sync.RWMutex
+
+	// 预计算的一轮选择序列，见 WithRotationBuffer
+	buffered bool
+	rotation atomic.Pointer[rotationBuffer]
+}
+
+// rotationBuffer 一整轮（长度等于参与选择节点的总权重）的选择序列
+// servers 与 nodes 一一对应，在计算时（持有锁）记录地址，读取时不再访问节点
+type rotationBuffer struct {
+	nodes   []*Node
+	servers []string
+	pos     atomic.Uint64
+}
+
+// SmoothOption 平滑加权轮询的可选配置
+type SmoothOption func(*smoothRoundRobinBalancer)
+
+// WithLess 自定义扫描时的比较规则，less(a, b) 返回 true 表示 a 比 b 更应该被选中
+// 默认规则见 defaultLess；自定义规则需要自己保证平局时的顺序，否则平局时保留先出现的节点
+// less 在持有锁时调用，拿到的是节点的快照
+func WithLess(less func(a, b NodeSnapshot) bool) SmoothOption {
+	return func(r *smoothRoundRobinBalancer) {
+		r.less = func(a, b *Node) bool {
+			return less(a.snapshot(), b.snapshot())
+		}
+	}
+}
+
+// WithMeasure 把每次 Next 的耗时（包括等锁的时间）上报给 sink，sink 为 nil 时不测量，没有额外开销
+func WithMeasure(sink TimingSink) SmoothOption {
+	return func(r *smoothRoundRobinBalancer) {
+		r.timing = sink
+	}
+}
+
+// WithRotationBuffer 预先计算一整轮（长度等于总权重）的选择序列，Next 只做一次原子递增，不加锁
+// 这一轮用完后才在锁内计算下一轮，适合读多写少的热路径
+// 代价是一致性有界地滞后：UpdateWeight/Drain/Undrain/Restore 会丢弃未用完的序列，
+// 但已经拿到旧序列的并发 Next 仍可能返回修改前的选择；Snapshot/CurrentWeights 反映的是预计算到的位置，
+// 而不是已经返回到的位置。缓冲区按每单位权重一个指针分配，总权重很大时不适合开启
+func WithRotationBuffer() SmoothOption {
+	return func(r *smoothRoundRobinBalancer) {
+		r.buffered = true
+	}
+}
+
+// WithTracer 每次成功选出节点后调用 fn，ctx 为传给 Next 的 ctx，方便把选中的服务器记录到链路追踪的 span 上
+// fn 在释放锁之后调用，可以安全地回调负载均衡器
+func WithTracer(fn func(ctx context.Context, selected string)) SmoothOption {
+	return func(r *smoothRoundRobinBalancer) {
+		r.tracer = fn
+	}
+}
+
+// defaultLess 当前权重更大的节点胜出，相等时地址字典序更小的胜出
+// 平局规则只依赖节点本身，与传入切片的顺序无关，相同配置总是得到相同的选择序列
+func defaultLess(a, b *Node) bool {
+	if a.current != b.current {
+		return a.current > b.current
+	}
+	return a.server < b.server
 }
 
 // NewSmoothRRBalancer
@@ -33,48 +154,348 @@ type smoothRoundRobinBalancer struct {
 // 3、比较节点自己的力气，是否大于总的力气
 // 4、如果大于总的力气，则返回。否则继迭代
 // 5、最后，选中的节点，要减掉力气
-func NewSmoothRRBalancer(nodes []*Node) SmoothBalancer {
+func NewSmoothRRBalancer(nodes []*Node, opts ...SmoothOption) SmoothBalancer {
+	return NewSmoothRRBalancerWithLimits(nodes, maxWeight, maxTotalWeight, opts...)
+}
+
+// NewSmoothRRBalancerE 与 NewSmoothRRBalancer 相同，但参数不合法（包括重复的地址）时返回错误而不是 panic
+func NewSmoothRRBalancerE(nodes []*Node, opts ...SmoothOption) (SmoothBalancer, error) {
+	r, err := newSmoothRR(nodes, maxWeight, maxTotalWeight, opts)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewSmoothRRBalancerWithLimits 使用自定义的单节点/总权重上限，总权重按 int64 累加并检查溢出
+func NewSmoothRRBalancerWithLimits(nodes []*Node, maxWeight, maxTotal int64, opts ...SmoothOption) SmoothBalancer {
+	r, err := newSmoothRR(nodes, maxWeight, maxTotal, opts)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func newSmoothRR(nodes []*Node, maxWeight, maxTotal int64, opts []SmoothOption) (*smoothRoundRobinBalancer, error) {
 	if len(nodes) == 0 {
-		panic(fmt.Errorf("new smooth rr failed: nodes is empty"))
+		return nil, fmt.Errorf("new smooth rr failed: %w", ErrEmptyPool)
 	}
-	totalWeight := 0
+	var totalWeight int64
 	for _, node := range nodes {
 		if node.weight <= 0 {
-			panic(fmt.Errorf("node weight must be positive, got: %d", node.weight))
+			return nil, fmt.Errorf("node weight must be positive, got: %d", node.weight)
+		}
+		if int64(node.weight) > maxWeight {
+			return nil, fmt.Errorf("node weight %d exceeds max %d", node.weight, maxWeight)
 		}
-		if node.weight > maxWeight {
-			panic(fmt.Errorf("node weight %d exceeds max %d", node.weight, maxWeight))
+		var ok bool
+		if totalWeight, ok = addWeight(totalWeight, int64(node.weight)); !ok {
+			return nil, fmt.Errorf("total weight overflows int64")
 		}
-		totalWeight += node.weight
 	}
 
-	if totalWeight > maxTotalWeight {
-		panic(fmt.Errorf("total weight %d exceeds max %d", totalWeight, maxTotalWeight))
+	if totalWeight > maxTotal {
+		return nil, fmt.Errorf("total weight %d exceeds max %d", totalWeight, maxTotal)
+	}
+	if err := checkDuplicates(nodes, (*Node).Server); err != nil {
+		return nil, err
 	}
-	return &smoothRoundRobinBalancer{
-		nodes: nodes,
+	r := &smoothRoundRobinBalancer{
+		nodes:     nodes,
+		less:      defaultLess,
+		maxWeight: maxWeight,
+		maxTotal:  maxTotal,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
 }
 
 func (r *smoothRoundRobinBalancer) Next(ctx context.Context) *Node {
+	node, _ := r.NextE(ctx)
+	return node
+}
+
+func (r *smoothRoundRobinBalancer) NextE(ctx context.Context) (*Node, error) {
+	if r.timing != nil {
+		start := time.Now()
+		defer func() {
+			r.timing.RecordNextDuration(time.Since(start))
+		}()
+	}
+
+	// 在加锁之前检查，已经取消的请求不占用锁，也不推进轮询状态
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	node, server, err := r.selectNode()
+	if r.tracer != nil && node != nil {
+		r.tracer(ctx, server)
+	}
+	return node, err
+}
+
+// selectNode 返回选中的节点以及持有锁时读到的地址，调用方不必在锁外读取节点
+func (r *smoothRoundRobinBalancer) selectNode() (*Node, string, error) {
+	if r.buffered {
+		return r.nextBuffered()
+	}
+
 	r.lock.Lock()
 	defer r.lock.Unlock()
+	node, err := r.next()
+	if err != nil {
+		return nil, "", err
+	}
+	return node, node.server, nil
+}
 
+// next 平滑加权轮询的一次选择，调用方持有写锁
+func (r *smoothRoundRobinBalancer) next() (*Node, error) {
+	if len(r.nodes) == 0 {
+		return nil, ErrEmptyPool
+	}
+	best := selectSmooth(r.nodes, r.less)
+	if best == nil {
+		return nil, ErrAllUnavailable
+	}
+	return best, nil
+}
+
+// selectSmooth 平滑加权轮询算法本身，不加锁，由调用方保证并发安全
+// 直接修改节点的当前权重并返回选中的节点；跳过被摘除的节点，没有可选节点时返回 nil
+// less 为 nil 时使用 defaultLess
+func selectSmooth(nodes []*Node, less func(a, b *Node) bool) *Node {
+	if less == nil {
+		less = defaultLess
+	}
 	var (
-		totalWeight = 0
+		totalWeight int64
 		bestNode    *Node
 	)
-	for _, node := range r.nodes {
-		node.current += node.weight
-		totalWeight += node.weight
+	for _, node := range nodes {
+		if node.drained {
+			continue
+		}
+		node.current += int64(node.weight)
+		totalWeight += int64(node.weight)
 
-		if bestNode == nil || node.current > bestNode.current {
+		if bestNode == nil || less(node, bestNode) {
 			bestNode = node
 		}
 	}
-
 	if bestNode != nil {
 		bestNode.current -= totalWeight
 	}
 	return bestNode
 }
+
+// nextBuffered 从预计算的序列中取下一个，序列用完或被丢弃时重新计算
+func (r *smoothRoundRobinBalancer) nextBuffered() (*Node, string, error) {
+	for {
+		if buf := r.rotation.Load(); buf != nil {
+			if i := buf.pos.Add(1) - 1; i < uint64(len(buf.nodes)) {
+				return buf.nodes[i], buf.servers[i], nil
+			}
+		}
+		if err := r.refill(); err != nil {
+			return nil, "", err
+		}
+	}
+}
+
+// refill 在锁内计算下一轮序列，并发的调用中只有第一个真正计算
+func (r *smoothRoundRobinBalancer) refill() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if buf := r.rotation.Load(); buf != nil && buf.pos.Load() < uint64(len(buf.nodes)) {
+		return nil
+	}
+	var totalWeight int64
+	for _, node := range r.nodes {
+		if !node.drained {
+			totalWeight += int64(node.weight)
+		}
+	}
+	if totalWeight == 0 {
+		// 与加锁路径返回相同的错误
+		_, err := r.next()
+		return err
+	}
+	nodes := make([]*Node, 0, totalWeight)
+	servers := make([]string, 0, totalWeight)
+	for i := int64(0); i < totalWeight; i++ {
+		node, _ := r.next()
+		nodes = append(nodes, node)
+		servers = append(servers, node.server)
+	}
+	r.rotation.Store(&rotationBuffer{nodes: nodes, servers: servers})
+	return nil
+}
+
+func (r *smoothRoundRobinBalancer) ReplaceAddr(old, new string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	target := -1
+	for i, node := range r.nodes {
+		if node.server == new {
+			return fmt.Errorf("server %s already exists", new)
+		}
+		if node.server == old {
+			target = i
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("server %s not found", old)
+	}
+	// 换成新节点而不是修改旧节点：Next 返回的节点可能正在锁外被读取
+	prev := r.nodes[target]
+	r.nodes = slices.Clone(r.nodes)
+	r.nodes[target] = &Node{
+		server:  new,
+		current: prev.current,
+		weight:  prev.weight,
+		drained: prev.drained,
+	}
+	// 预计算的序列里还是旧节点
+	r.rotation.Store(nil)
+	return nil
+}
+
+func (r *smoothRoundRobinBalancer) UpdateWeight(server string, weight int) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if weight <= 0 {
+		return fmt.Errorf("node weight must be positive, got: %d", weight)
+	}
+	if int64(weight) > r.maxWeight {
+		return fmt.Errorf("node weight %d exceeds max %d", weight, r.maxWeight)
+	}
+
+	var (
+		target      *Node
+		totalWeight int64
+	)
+	for _, node := range r.nodes {
+		w := int64(node.weight)
+		if node.server == server {
+			target = node
+			w = int64(weight)
+		}
+		var ok bool
+		if totalWeight, ok = addWeight(totalWeight, w); !ok {
+			return fmt.Errorf("total weight overflows int64")
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("server %s not found", server)
+	}
+	if totalWeight > r.maxTotal {
+		return fmt.Errorf("total weight %d exceeds max %d", totalWeight, r.maxTotal)
+	}
+
+	target.weight = weight
+	for _, node := range r.nodes {
+		node.current = 0
+	}
+	r.rotation.Store(nil)
+	return nil
+}
+
+func (r *smoothRoundRobinBalancer) Snapshot() map[string]int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	state := make(map[string]int, len(r.nodes))
+	for _, node := range r.nodes {
+		state[node.server] = int(node.current)
+	}
+	return state
+}
+
+// CurrentWeights 与 Snapshot 返回相同的数据，只是用途不同
+// 没有摘除节点时，所有节点的当前权重之和总是为0：每次选择先给每个节点加上权重，再从选中节点减去总权重
+func (r *smoothRoundRobinBalancer) CurrentWeights() map[string]int {
+	return r.Snapshot()
+}
+
+// Restore 不在 state 中的节点保持当前权重不变
+func (r *smoothRoundRobinBalancer) Restore(state map[string]int) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	index := make(map[string]*Node, len(r.nodes))
+	for _, node := range r.nodes {
+		index[node.server] = node
+	}
+	for server := range state {
+		if _, ok := index[server]; !ok {
+			return fmt.Errorf("server %s not found", server)
+		}
+	}
+	for server, current := range state {
+		index[server].current = int64(current)
+	}
+	r.rotation.Store(nil)
+	return nil
+}
+
+func (r *smoothRoundRobinBalancer) Drain(server string) error {
+	return r.setDrained(server, true)
+}
+
+func (r *smoothRoundRobinBalancer) Undrain(server string) error {
+	return r.setDrained(server, false)
+}
+
+// setDrained 参与选择的节点集合变化后重置所有节点的当前权重，让分布重新收敛
+func (r *smoothRoundRobinBalancer) setDrained(server string, drained bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var target *Node
+	for _, node := range r.nodes {
+		if node.server == server {
+			target = node
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("server %s not found", server)
+	}
+	if target.drained == drained {
+		return nil
+	}
+	target.drained = drained
+	for _, node := range r.nodes {
+		node.current = 0
+	}
+	r.rotation.Store(nil)
+	return nil
+}
+
+// RemoveServer 移除后总权重只会变小，不会超过上限，不需要重新校验
+// 与 Drain 一样重置所有节点的当前权重，让剩余节点的分布重新收敛
+func (r *smoothRoundRobinBalancer) RemoveServer(server string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := slices.IndexFunc(r.nodes, func(node *Node) bool {
+		return node.server == server
+	})
+	if i < 0 {
+		return false
+	}
+	// 复制后再删除，不修改调用方传入的切片
+	r.nodes = slices.Delete(slices.Clone(r.nodes), i, i+1)
+	for _, node := range r.nodes {
+		node.current = 0
+	}
+	r.rotation.Store(nil)
+	return true
+}
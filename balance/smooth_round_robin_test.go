@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 )
 
 // TestSmoothRRBasic 测试基本权重分布
@@ -271,3 +272,695 @@ func BenchmarkSmoothRRParallel(b *testing.B) {
 		}
 	})
 }
+
+// TestSmoothRRReplaceAddr 测试替换地址后当前权重延续
+func TestSmoothRRReplaceAddr(t *testing.T) {
+	newNodes := func() []*Node {
+		return []*Node{
+			{server: "a", weight: 5},
+			{server: "b", weight: 1},
+			{server: "c", weight: 1},
+		}
+	}
+	balancer := NewSmoothRRBalancer(newNodes())
+	reference := NewSmoothRRBalancer(newNodes())
+
+	for i := 0; i < 3; i++ {
+		balancer.Next(context.Background())
+		reference.Next(context.Background())
+	}
+
+	if err := balancer.ReplaceAddr("a", "a2"); err != nil {
+		t.Fatalf("ReplaceAddr() error: %v", err)
+	}
+
+	// 替换后的序列应与未替换时完全一致，只是地址变了
+	counts := make(map[string]int)
+	for i := 0; i < 14; i++ {
+		got := balancer.Next(context.Background()).server
+		want := reference.Next(context.Background()).server
+		if want == "a" {
+			want = "a2"
+		}
+		if got != want {
+			t.Errorf("iteration %d: got %s, want %s", i, got, want)
+		}
+		counts[got]++
+	}
+	if counts["a2"] != 10 || counts["a"] != 0 {
+		t.Errorf("unexpected distribution after replace: %v", counts)
+	}
+}
+
+// TestSmoothRRReplaceAddrKeepsOldNode 替换地址不修改调用方已经拿到的节点
+func TestSmoothRRReplaceAddrKeepsOldNode(t *testing.T) {
+	for _, opts := range [][]SmoothOption{nil, {WithRotationBuffer()}} {
+		nodes := []*Node{NewNode("a", 1), NewNode("b", 1)}
+		balancer := NewSmoothRRBalancer(nodes, opts...)
+		held := balancer.Next(context.Background())
+
+		if err := balancer.ReplaceAddr(held.Server(), "x"); err != nil {
+			t.Fatalf("ReplaceAddr() error: %v", err)
+		}
+		if held.Server() == "x" || nodes[0].Server() != "a" {
+			t.Errorf("ReplaceAddr modified a node held by the caller: %v, %v", held.Server(), nodes[0].Server())
+		}
+		seen := make(map[string]bool)
+		for i := 0; i < 4; i++ {
+			seen[balancer.Next(context.Background()).Server()] = true
+		}
+		if !seen["x"] || seen["a"] {
+			t.Errorf("after ReplaceAddr selected %v, want x and b only", seen)
+		}
+	}
+}
+
+// TestSmoothRRReplaceAddrErrors 测试替换地址的错误情况
+func TestSmoothRRReplaceAddrErrors(t *testing.T) {
+	balancer := NewSmoothRRBalancer([]*Node{
+		{server: "a", weight: 1},
+		{server: "b", weight: 1},
+	})
+
+	if err := balancer.ReplaceAddr("missing", "x"); err == nil {
+		t.Error("expected error for unknown server")
+	}
+	if err := balancer.ReplaceAddr("a", "b"); err == nil {
+		t.Error("expected error for existing target address")
+	}
+}
+
+// TestSmoothRRCustomLess 测试自定义比较规则只影响平局
+func TestSmoothRRCustomLess(t *testing.T) {
+	newNodes := func() []*Node {
+		return []*Node{
+			{server: "c", weight: 1},
+			{server: "b", weight: 1},
+			{server: "a", weight: 2},
+		}
+	}
+	byAddrDesc := WithLess(func(a, b NodeSnapshot) bool {
+		if a.Current != b.Current {
+			return a.Current > b.Current
+		}
+		return a.Server > b.Server
+	})
+
+	defaultSeq := make([]string, 0, 4)
+	customSeq := make([]string, 0, 4)
+	def := NewSmoothRRBalancer(newNodes())
+	custom := NewSmoothRRBalancer(newNodes(), byAddrDesc)
+	for i := 0; i < 4; i++ {
+		defaultSeq = append(defaultSeq, def.Next(context.Background()).server)
+		customSeq = append(customSeq, custom.Next(context.Background()).server)
+	}
+
+	// 第二次选择时 c 和 b 的当前权重相等：默认按地址取 b，自定义按地址倒序取 c
+	wantDefault := []string{"a", "b", "c", "a"}
+	wantCustom := []string{"a", "c", "b", "a"}
+	for i := range wantDefault {
+		if defaultSeq[i] != wantDefault[i] {
+			t.Errorf("default sequence = %v, want %v", defaultSeq, wantDefault)
+			break
+		}
+	}
+	for i := range wantCustom {
+		if customSeq[i] != wantCustom[i] {
+			t.Errorf("custom sequence = %v, want %v", customSeq, wantCustom)
+			break
+		}
+	}
+
+	// 没有平局时两者一致
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		counts[custom.Next(context.Background()).server]++
+	}
+	if counts["a"] != 200 || counts["b"] != 100 || counts["c"] != 100 {
+		t.Errorf("custom less changed distribution: %v", counts)
+	}
+}
+
+type timingRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func (r *timingRecorder) RecordNextDuration(d time.Duration) {
+	r.mu.Lock()
+	r.samples = append(r.samples, d)
+	r.mu.Unlock()
+}
+
+// TestSmoothRRMeasure 开启测量后每次 Next 都上报一个耗时样本
+func TestSmoothRRMeasure(t *testing.T) {
+	sink := &timingRecorder{}
+	nodes := []*Node{
+		{server: "a", weight: 2},
+		{server: "b", weight: 1},
+	}
+	balancer := NewSmoothRRBalancer(nodes, WithMeasure(sink))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				balancer.Next(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(sink.samples) != 200 {
+		t.Fatalf("got %d samples, want 200", len(sink.samples))
+	}
+	for _, d := range sink.samples {
+		if d < 0 {
+			t.Errorf("negative duration %v", d)
+		}
+	}
+}
+
+// TestSmoothRRMeasureDisabled 未开启时不测量
+func TestSmoothRRMeasureDisabled(t *testing.T) {
+	nodes := []*Node{{server: "a", weight: 1}}
+	balancer := NewSmoothRRBalancer(nodes, WithMeasure(nil))
+	if got := balancer.Next(context.Background()); got.Server() != "a" {
+		t.Errorf("Next() = %v, want a", got.Server())
+	}
+	if r := balancer.(*smoothRoundRobinBalancer); r.timing != nil {
+		t.Error("timing should be nil when disabled")
+	}
+}
+
+// TestSmoothRRBalancerE 参数不合法时返回错误而不是 panic
+func TestSmoothRRBalancerE(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []*Node
+		wantErr string
+	}{
+		{"empty", nil, "new smooth rr failed: server pool is empty"},
+		{"zero weight", []*Node{{server: "a", weight: 0}}, "node weight must be positive, got: 0"},
+		{"negative weight", []*Node{{server: "a", weight: -3}}, "node weight must be positive, got: -3"},
+		{"weight exceeds max", []*Node{{server: "a", weight: maxWeight + 1}}, "node weight 1000001 exceeds max 1000000"},
+		{"total exceeds max", func() []*Node {
+			nodes := make([]*Node, 11)
+			for i := range nodes {
+				nodes[i] = &Node{server: "n", weight: maxWeight}
+			}
+			return nodes
+		}(), "total weight 11000000 exceeds max 10000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewSmoothRRBalancerE(tt.nodes)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("NewSmoothRRBalancerE() error = %v, want %q", err, tt.wantErr)
+			}
+			if b != nil {
+				t.Errorf("NewSmoothRRBalancerE() balancer = %v, want nil", b)
+			}
+		})
+	}
+
+	b, err := NewSmoothRRBalancerE([]*Node{{server: "a", weight: 1}})
+	if err != nil {
+		t.Fatalf("NewSmoothRRBalancerE() error = %v", err)
+	}
+	if got := b.Next(context.Background()).Server(); got != "a" {
+		t.Errorf("Next() = %v, want a", got)
+	}
+}
+
+// TestSmoothRRUpdateWeight 运行中调整权重，分布按新权重收敛
+func TestSmoothRRUpdateWeight(t *testing.T) {
+	nodes := []*Node{
+		{server: "a", weight: 1},
+		{server: "b", weight: 1},
+	}
+	balancer := NewSmoothRRBalancer(nodes)
+	for i := 0; i < 5; i++ {
+		balancer.Next(context.Background())
+	}
+
+	if err := balancer.UpdateWeight("a", 3); err != nil {
+		t.Fatalf("UpdateWeight() error = %v", err)
+	}
+	for _, node := range nodes {
+		if node.current != 0 {
+			t.Errorf("node %s current = %d after update, want 0", node.Server(), node.current)
+		}
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		counts[balancer.Next(context.Background()).Server()]++
+	}
+	if counts["a"] != 3000 || counts["b"] != 1000 {
+		t.Errorf("distribution = %v, want a=3000 b=1000", counts)
+	}
+}
+
+// TestSmoothRRUpdateWeightErrors 非法的权重或不存在的节点返回错误，权重保持不变
+func TestSmoothRRUpdateWeightErrors(t *testing.T) {
+	nodes := []*Node{
+		{server: "a", weight: 1},
+		{server: "b", weight: 1},
+	}
+	balancer := NewSmoothRRBalancerWithLimits(nodes, 10, 15)
+
+	tests := []struct {
+		server  string
+		weight  int
+		wantErr string
+	}{
+		{"c", 1, "server c not found"},
+		{"a", 0, "node weight must be positive, got: 0"},
+		{"a", 11, "node weight 11 exceeds max 10"},
+		{"a", 10, ""},
+		{"b", 6, "total weight 16 exceeds max 15"},
+	}
+	for _, tt := range tests {
+		err := balancer.UpdateWeight(tt.server, tt.weight)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("UpdateWeight(%s, %d) error = %v", tt.server, tt.weight, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("UpdateWeight(%s, %d) error = %v, want %q", tt.server, tt.weight, err, tt.wantErr)
+		}
+	}
+	if nodes[1].Weight() != 1 {
+		t.Errorf("b weight = %d after failed update, want 1", nodes[1].Weight())
+	}
+}
+
+// TestSmoothRRCancelledContext 已取消的 ctx 不选择节点，也不改变轮询状态
+func TestSmoothRRCancelledContext(t *testing.T) {
+	nodes := []*Node{
+		{server: "a", weight: 2},
+		{server: "b", weight: 1},
+	}
+	balancer := NewSmoothRRBalancer(nodes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if got := balancer.Next(ctx); got != nil {
+		t.Errorf("Next() = %v, want nil for cancelled context", got.Server())
+	}
+	if got, err := balancer.NextE(ctx); got != nil || err != context.Canceled {
+		t.Errorf("NextE() = %v, %v, want nil, context.Canceled", got, err)
+	}
+	for _, node := range nodes {
+		if node.current != 0 {
+			t.Errorf("node %s current = %d, want untouched 0", node.Server(), node.current)
+		}
+	}
+
+	if got, err := balancer.NextE(context.Background()); err != nil || got.Server() != "a" {
+		t.Errorf("NextE() = %v, %v, want a, nil", got, err)
+	}
+}
+
+// TestSmoothRRSnapshotRestore 新实例恢复快照后，选择序列与原实例一致
+func TestSmoothRRSnapshotRestore(t *testing.T) {
+	newNodes := func() []*Node {
+		return []*Node{
+			{server: "a", weight: 5},
+			{server: "b", weight: 1},
+			{server: "c", weight: 1},
+		}
+	}
+	original := NewSmoothRRBalancer(newNodes())
+	for i := 0; i < 3; i++ {
+		original.Next(context.Background())
+	}
+	state := original.Snapshot()
+
+	restored := NewSmoothRRBalancer(newNodes())
+	if err := restored.Restore(state); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	for i := 0; i < 14; i++ {
+		want := original.Next(context.Background()).Server()
+		if got := restored.Next(context.Background()).Server(); got != want {
+			t.Errorf("call %d: restored Next() = %v, want %v", i, got, want)
+		}
+	}
+}
+
+// TestSmoothRRRestoreUnknown 未知节点返回错误，不做任何修改
+func TestSmoothRRRestoreUnknown(t *testing.T) {
+	nodes := []*Node{{server: "a", weight: 1}}
+	balancer := NewSmoothRRBalancer(nodes)
+
+	err := balancer.Restore(map[string]int{"a": 3, "x": 1})
+	if err == nil || err.Error() != "server x not found" {
+		t.Errorf("Restore() error = %v, want server x not found", err)
+	}
+	if nodes[0].current != 0 {
+		t.Errorf("a current = %d, want unchanged 0", nodes[0].current)
+	}
+}
+
+// TestSmoothRRStableTieBreak 平局按地址字典序，选择序列与传入切片的顺序无关
+func TestSmoothRRStableTieBreak(t *testing.T) {
+	orders := [][]string{
+		{"a", "b", "c", "d"},
+		{"d", "c", "b", "a"},
+		{"c", "a", "d", "b"},
+	}
+	want := []string{"a", "b", "c", "d", "a", "b", "c", "d"}
+	for _, order := range orders {
+		nodes := make([]*Node, 0, len(order))
+		for _, s := range order {
+			nodes = append(nodes, NewNode(s, 1))
+		}
+		balancer := NewSmoothRRBalancer(nodes)
+		for i, w := range want {
+			if got := balancer.Next(context.Background()).Server(); got != w {
+				t.Errorf("order %v call %d: Next() = %v, want %v", order, i, got, w)
+			}
+		}
+	}
+}
+
+// TestSmoothRRDrain 摘除权重最大的节点后，流量按其余节点的权重分配
+func TestSmoothRRDrain(t *testing.T) {
+	nodes := []*Node{
+		{server: "a", weight: 5},
+		{server: "b", weight: 1},
+		{server: "c", weight: 3},
+	}
+	balancer := NewSmoothRRBalancer(nodes)
+	balancer.Next(context.Background())
+
+	if err := balancer.Drain("a"); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		counts[balancer.Next(context.Background()).Server()]++
+	}
+	if counts["a"] != 0 || counts["b"] != 100 || counts["c"] != 300 {
+		t.Errorf("distribution after drain = %v, want a=0 b=100 c=300", counts)
+	}
+
+	// 全部摘除时返回 nil
+	balancer.Drain("b")
+	balancer.Drain("c")
+	if got := balancer.Next(context.Background()); got != nil {
+		t.Errorf("Next() = %v, want nil when all drained", got.Server())
+	}
+	if _, err := balancer.NextE(context.Background()); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+
+	// 恢复后权重不变
+	balancer.Undrain("a")
+	balancer.Undrain("b")
+	balancer.Undrain("c")
+	counts = make(map[string]int)
+	for i := 0; i < 900; i++ {
+		counts[balancer.Next(context.Background()).Server()]++
+	}
+	if counts["a"] != 500 || counts["b"] != 100 || counts["c"] != 300 {
+		t.Errorf("distribution after undrain = %v, want a=500 b=100 c=300", counts)
+	}
+
+	if err := balancer.Drain("x"); err == nil {
+		t.Error("Drain() unknown server should fail")
+	}
+}
+
+// TestSmoothRRCurrentWeights 当前权重之和保持为0，一个完整周期后全部回到0
+func TestSmoothRRCurrentWeights(t *testing.T) {
+	balancer := NewSmoothRRBalancer([]*Node{
+		{server: "a", weight: 5},
+		{server: "b", weight: 1},
+		{server: "c", weight: 1},
+	})
+
+	for i := 1; i <= 14; i++ {
+		node := balancer.Next(context.Background())
+		weights := balancer.CurrentWeights()
+		sum := 0
+		for _, c := range weights {
+			sum += c
+		}
+		if sum != 0 {
+			t.Fatalf("call %d: sum of currents = %d, want 0 (%v)", i, sum, weights)
+		}
+		if weights[node.Server()] != int(node.current) {
+			t.Errorf("call %d: CurrentWeights()[%s] = %d, want %d", i, node.Server(), weights[node.Server()], node.current)
+		}
+		if i%7 == 0 {
+			for server, c := range weights {
+				if c != 0 {
+					t.Errorf("after %d full cycles %s current = %d, want 0", i/7, server, c)
+				}
+			}
+		}
+	}
+}
+
+// TestSmoothRRRotationBuffer 预计算序列与加锁路径的选择序列一致，分布严格符合权重
+func TestSmoothRRRotationBuffer(t *testing.T) {
+	locked := NewSmoothRRBalancer([]*Node{NewNode("a", 3), NewNode("b", 2), NewNode("c", 1)})
+	buffered := NewSmoothRRBalancer([]*Node{NewNode("a", 3), NewNode("b", 2), NewNode("c", 1)}, WithRotationBuffer())
+
+	counts := make(map[string]int)
+	for i := 0; i < 600; i++ {
+		want := locked.Next(context.Background()).Server()
+		got := buffered.Next(context.Background()).Server()
+		if got != want {
+			t.Fatalf("call %d: buffered Next() = %v, locked Next() = %v", i, got, want)
+		}
+		counts[got]++
+	}
+	if counts["a"] != 300 || counts["b"] != 200 || counts["c"] != 100 {
+		t.Errorf("distribution = %v, want a=300 b=200 c=100", counts)
+	}
+}
+
+// TestSmoothRRRotationBufferInvalidate 修改节点后丢弃未用完的序列
+func TestSmoothRRRotationBufferInvalidate(t *testing.T) {
+	balancer := NewSmoothRRBalancer([]*Node{NewNode("a", 3), NewNode("b", 1)}, WithRotationBuffer())
+	balancer.Next(context.Background())
+
+	if err := balancer.Drain("a"); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if got := balancer.Next(context.Background()).Server(); got != "b" {
+			t.Errorf("Next() = %v, want b after draining a", got)
+		}
+	}
+
+	if err := balancer.Drain("b"); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if _, err := balancer.NextE(context.Background()); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
+
+// TestSmoothRRRotationBufferConcurrency 并发取用时每一轮都完整地按权重分配
+func TestSmoothRRRotationBufferConcurrency(t *testing.T) {
+	balancer := NewSmoothRRBalancer([]*Node{NewNode("a", 5), NewNode("b", 3), NewNode("c", 2)}, WithRotationBuffer())
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		counts = make(map[string]int)
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make(map[string]int)
+			for j := 0; j < 1000; j++ {
+				local[balancer.Next(context.Background()).Server()]++
+			}
+			mu.Lock()
+			for k, v := range local {
+				counts[k] += v
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counts["a"] != 5000 || counts["b"] != 3000 || counts["c"] != 2000 {
+		t.Errorf("distribution = %v, want a=5000 b=3000 c=2000", counts)
+	}
+}
+
+// BenchmarkSmoothRRParallelBuffered 与 BenchmarkSmoothRRParallel 对比预计算序列的吞吐
+func BenchmarkSmoothRRParallelBuffered(b *testing.B) {
+	balancer := NewSmoothRRBalancer([]*Node{NewNode("a", 5), NewNode("b", 3), NewNode("c", 2)}, WithRotationBuffer())
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			balancer.Next(context.Background())
+		}
+	})
+}
+
+// TestSmoothRRRemoveServer 运行中移除节点后剩余节点按权重比例分配
+func TestSmoothRRRemoveServer(t *testing.T) {
+	nodes := []*Node{NewNode("a", 3), NewNode("b", 2), NewNode("c", 1)}
+	balancer := NewSmoothRRBalancer(nodes)
+	for i := 0; i < 4; i++ {
+		balancer.Next(context.Background())
+	}
+
+	if !balancer.RemoveServer("a") {
+		t.Fatal("RemoveServer(a) = false, want true")
+	}
+	if balancer.RemoveServer("a") {
+		t.Error("RemoveServer(a) again = true, want false")
+	}
+	if nodes[0].Server() != "a" {
+		t.Errorf("caller's slice was modified: %v", nodes[0].Server())
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		counts[balancer.Next(context.Background()).Server()]++
+	}
+	if counts["a"] != 0 || counts["b"] != 200 || counts["c"] != 100 {
+		t.Errorf("distribution after remove = %v, want b=200 c=100", counts)
+	}
+
+	balancer.RemoveServer("b")
+	balancer.RemoveServer("c")
+	if node := balancer.Next(context.Background()); node != nil {
+		t.Errorf("Next() = %v, want nil after removing every server", node.Server())
+	}
+}
+
+type traceCtxKey struct{}
+
+// TestSmoothRRTracer 每次选择后调用 tracer，传入调用方的 ctx 和选中的服务器
+func TestSmoothRRTracer(t *testing.T) {
+	type call struct {
+		span     any
+		selected string
+	}
+	var (
+		calls    []call
+		balancer SmoothBalancer
+	)
+	balancer = NewSmoothRRBalancer([]*Node{NewNode("a", 2), NewNode("b", 1)}, WithTracer(func(ctx context.Context, selected string) {
+		// 在锁外调用，回调负载均衡器不会死锁
+		balancer.CurrentWeights()
+		calls = append(calls, call{span: ctx.Value(traceCtxKey{}), selected: selected})
+	}))
+
+	var want []string
+	for i := 0; i < 3; i++ {
+		ctx := context.WithValue(context.Background(), traceCtxKey{}, i)
+		want = append(want, balancer.Next(ctx).Server())
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("tracer called %d times, want %d", len(calls), len(want))
+	}
+	for i, c := range calls {
+		if c.span != i || c.selected != want[i] {
+			t.Errorf("call %d = %v, want span %d selected %v", i, c, i, want[i])
+		}
+	}
+
+	// 取消的 ctx 不会选择，也不会调用 tracer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	balancer.Next(ctx)
+	if len(calls) != 3 {
+		t.Errorf("tracer called for a cancelled context")
+	}
+}
+
+// TestSmoothRRTracerConcurrentReplace tracer 拿到的地址在锁内读取，与 ReplaceAddr 并发时没有数据竞争
+func TestSmoothRRTracerConcurrentReplace(t *testing.T) {
+	for _, opts := range [][]SmoothOption{nil, {WithRotationBuffer()}} {
+		balancer := NewSmoothRRBalancer([]*Node{NewNode("a", 2), NewNode("b", 1)},
+			append(opts, WithTracer(func(ctx context.Context, selected string) {
+				if selected == "" {
+					t.Error("tracer got empty address")
+				}
+			}))...)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if i%2 == 0 {
+					balancer.ReplaceAddr("a", "a2")
+				} else {
+					balancer.ReplaceAddr("a2", "a")
+				}
+			}
+		}()
+		for i := 0; i < 300; i++ {
+			balancer.Next(context.Background())
+		}
+		wg.Wait()
+	}
+}
+
+// TestSelectSmoothEqualWeights 权重相同时按地址顺序轮流选择
+func TestSelectSmoothEqualWeights(t *testing.T) {
+	nodes := []*Node{NewNode("c", 5), NewNode("a", 5), NewNode("b", 5)}
+	want := []string{"a", "b", "c", "a", "b", "c"}
+	for i, w := range want {
+		if got := selectSmooth(nodes, nil).Server(); got != w {
+			t.Errorf("call %d: selectSmooth() = %v, want %v", i, got, w)
+		}
+	}
+}
+
+// TestSelectSmoothExtremeRatio 极端的权重比例下，一轮中轻节点恰好被选中一次，且所有节点的当前权重之和始终为0
+func TestSelectSmoothExtremeRatio(t *testing.T) {
+	nodes := []*Node{NewNode("heavy", 1000), NewNode("light", 1)}
+	counts := make(map[string]int)
+	for i := 0; i < 1001; i++ {
+		counts[selectSmooth(nodes, defaultLess).Server()]++
+		if sum := nodes[0].current + nodes[1].current; sum != 0 {
+			t.Fatalf("call %d: sum of current weights = %d, want 0", i, sum)
+		}
+	}
+	if counts["heavy"] != 1000 || counts["light"] != 1 {
+		t.Errorf("counts = %v, want heavy=1000 light=1", counts)
+	}
+	// 一轮结束后回到初始状态
+	if nodes[0].current != 0 || nodes[1].current != 0 {
+		t.Errorf("current weights after a full round = %d, %d, want 0, 0", nodes[0].current, nodes[1].current)
+	}
+}
+
+// TestSelectSmoothDrained 跳过被摘除的节点，全部摘除时返回 nil 且不修改当前权重
+func TestSelectSmoothDrained(t *testing.T) {
+	nodes := []*Node{NewNode("a", 1), NewNode("b", 2)}
+	nodes[1].drained = true
+	for i := 0; i < 3; i++ {
+		if got := selectSmooth(nodes, nil).Server(); got != "a" {
+			t.Errorf("selectSmooth() = %v, want a", got)
+		}
+	}
+	nodes[0].drained = true
+	if got := selectSmooth(nodes, nil); got != nil {
+		t.Errorf("selectSmooth() = %v, want nil when all drained", got.Server())
+	}
+	if got := selectSmooth(nil, nil); got != nil {
+		t.Errorf("selectSmooth(nil) = %v, want nil", got.Server())
+	}
+}
@@ -0,0 +1,185 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind 由服务发现结果构造负载均衡器时使用的算法
+type Kind int
+
+const (
+	// KindWeightedPriority SRV 优先级映射为层级，层内按 SRV 权重随机
+	KindWeightedPriority Kind = iota
+	// KindRoundRobin 只使用优先级最高的一层，忽略权重轮询
+	KindRoundRobin
+)
+
+const (
+	// srvRefreshInterval Go 的解析器不返回 TTL，使用固定的刷新间隔
+	srvRefreshInterval = 30 * time.Second
+	srvLookupTimeout   = 5 * time.Second
+)
+
+type srvLookupFunc func(ctx context.Context) ([]*net.SRV, error)
+
+type balancerHolder struct {
+	balancer Balancer
+}
+
+// SRVBalancer
+// 根据 DNS SRV 记录构造的负载均衡器，后台定期重新解析，解析失败时保留上一次的结果
+// 使用完需要调用 Close 停止后台刷新
+type SRVBalancer struct {
+	lookup  srvLookupFunc
+	kind    Kind
+	current atomic.Pointer[balancerHolder]
+
+	stop      chan struct{}
+	done      chan struct{}
+	closed    atomic.Bool
+	closeOnce sync.Once
+}
+
+// NewBalancerFromSRV 解析 SRV 记录构造负载均衡器，service 是完整的记录名，比如 _http._tcp.example.com
+// resolver 为 nil 时使用 net.DefaultResolver
+func NewBalancerFromSRV(service string, resolver *net.Resolver, kind Kind) (Balancer, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	lookup := func(ctx context.Context) ([]*net.SRV, error) {
+		_, records, err := resolver.LookupSRV(ctx, "", "", service)
+		return records, err
+	}
+	return newSRVBalancer(lookup, kind, srvRefreshInterval)
+}
+
+func newSRVBalancer(lookup srvLookupFunc, kind Kind, refresh time.Duration) (*SRVBalancer, error) {
+	s := &SRVBalancer{
+		lookup: lookup,
+		kind:   kind,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	go s.run(refresh)
+	return s, nil
+}
+
+func (s *SRVBalancer) Next() string {
+	addr, _ := s.NextE()
+	return addr
+}
+
+func (s *SRVBalancer) NextE() (string, error) {
+	if s.closed.Load() {
+		return "", ErrClosed
+	}
+	b := s.current.Load().balancer
+	if eb, ok := b.(ErrorBalancer); ok {
+		return eb.NextE()
+	}
+	addr := b.Next()
+	if addr == "" {
+		return "", ErrAllUnavailable
+	}
+	return addr, nil
+}
+
+// Close 停止后台刷新，可以重复调用
+func (s *SRVBalancer) Close() error {
+	s.closeOnce.Do(func() {
+		s.closed.Store(true)
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+func (s *SRVBalancer) run(refresh time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			// 刷新失败时保留上一次的结果
+			_ = s.refresh()
+		}
+	}
+}
+
+func (s *SRVBalancer) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), srvLookupTimeout)
+	defer cancel()
+
+	records, err := s.lookup(ctx)
+	if err != nil {
+		return fmt.Errorf("lookup srv failed: %w", err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("lookup srv failed: no records")
+	}
+	b, err := balancerFromSRV(records, s.kind)
+	if err != nil {
+		return fmt.Errorf("build balancer from srv failed: %w", err)
+	}
+	s.current.Store(&balancerHolder{balancer: b})
+	return nil
+}
+
+// balancerFromSRV SRV 优先级数值越小越优先；同一优先级的权重全为0时，按相等权重处理
+func balancerFromSRV(records []*net.SRV, kind Kind) (Balancer, error) {
+	byPriority := make(map[uint16][]*net.SRV)
+	var priorities []uint16
+	for _, r := range records {
+		if _, ok := byPriority[r.Priority]; !ok {
+			priorities = append(priorities, r.Priority)
+		}
+		byPriority[r.Priority] = append(byPriority[r.Priority], r)
+	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] < priorities[j] })
+
+	if kind == KindRoundRobin {
+		var servers []string
+		for _, r := range byPriority[priorities[0]] {
+			servers = append(servers, srvAddr(r))
+		}
+		return NewRoundRobinBalancer(servers), nil
+	}
+
+	tiers := make([]WeightedTier, 0, len(priorities))
+	for _, p := range priorities {
+		allZero := true
+		for _, r := range byPriority[p] {
+			if r.Weight > 0 {
+				allZero = false
+			}
+		}
+		var tier WeightedTier
+		for _, r := range byPriority[p] {
+			weight := int(r.Weight)
+			if allZero {
+				weight = 1
+			}
+			tier.Servers = append(tier.Servers, &Server{Addr: srvAddr(r), Weight: weight})
+		}
+		tiers = append(tiers, tier)
+	}
+	return NewWeightedPriorityBalancer(tiers)
+}
+
+func srvAddr(r *net.SRV) string {
+	return net.JoinHostPort(strings.TrimSuffix(r.Target, "."), strconv.Itoa(int(r.Port)))
+}
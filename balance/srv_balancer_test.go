@@ -0,0 +1,132 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func stubSRV(records []*net.SRV) srvLookupFunc {
+	return func(ctx context.Context) ([]*net.SRV, error) {
+		return records, nil
+	}
+}
+
+func TestSRVBalancer_TieredWeighted(t *testing.T) {
+	balancer, err := newSRVBalancer(stubSRV([]*net.SRV{
+		{Target: "backup.example.com.", Port: 80, Priority: 20, Weight: 1},
+		{Target: "a.example.com.", Port: 80, Priority: 10, Weight: 30},
+		{Target: "b.example.com.", Port: 8080, Priority: 10, Weight: 10},
+	}), KindWeightedPriority, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer balancer.Close()
+
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.Next()]++
+	}
+	t.Logf("Distribution: %v", results)
+
+	if results["backup.example.com:80"] > 0 {
+		t.Errorf("lower priority tier should not be used: %v", results)
+	}
+	ratio := float64(results["a.example.com:80"]) / float64(results["b.example.com:8080"])
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected a/b ratio around 3.0, got %.2f", ratio)
+	}
+
+	// 第一层全部不可用后流向第二层
+	tiered := balancer.current.Load().balancer.(*WeightedPriorityBalancer)
+	tiered.SetAvailable("a.example.com:80", false)
+	tiered.SetAvailable("b.example.com:8080", false)
+	if got := balancer.Next(); got != "backup.example.com:80" {
+		t.Errorf("Next() = %v, want backup.example.com:80", got)
+	}
+}
+
+func TestSRVBalancer_RoundRobinKind(t *testing.T) {
+	balancer, err := newSRVBalancer(stubSRV([]*net.SRV{
+		{Target: "a.example.com.", Port: 80, Priority: 1, Weight: 100},
+		{Target: "b.example.com.", Port: 80, Priority: 1, Weight: 1},
+		{Target: "c.example.com.", Port: 80, Priority: 2, Weight: 1},
+	}), KindRoundRobin, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer balancer.Close()
+
+	// 起点随机，但只在最高优先级的 a、b 之间交替
+	first, second := balancer.Next(), balancer.Next()
+	pair := map[string]bool{first: true, second: true}
+	if !pair["a.example.com:80"] || !pair["b.example.com:80"] {
+		t.Errorf("Next() = %v, %v, want a.example.com:80 and b.example.com:80 alternating", first, second)
+	}
+	if got := balancer.Next(); got != first {
+		t.Errorf("Next() = %v, want %v", got, first)
+	}
+}
+
+func TestSRVBalancer_Refresh(t *testing.T) {
+	var calls atomic.Int32
+	lookup := func(ctx context.Context) ([]*net.SRV, error) {
+		if calls.Add(1) == 1 {
+			return []*net.SRV{{Target: "old.", Port: 1, Weight: 1}}, nil
+		}
+		return []*net.SRV{{Target: "new.", Port: 1, Weight: 1}}, nil
+	}
+	balancer, err := newSRVBalancer(lookup, KindWeightedPriority, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer balancer.Close()
+
+	if got := balancer.Next(); got != "old:1" {
+		t.Errorf("Next() = %v, want old:1", got)
+	}
+	waitFor(t, func() bool { return balancer.Next() == "new:1" }, "balancer was not refreshed")
+
+	balancer.Close()
+	if _, err := balancer.NextE(); !errors.Is(err, ErrClosed) {
+		t.Errorf("NextE() error = %v, want ErrClosed", err)
+	}
+}
+
+func TestSRVBalancer_LookupError(t *testing.T) {
+	lookup := func(ctx context.Context) ([]*net.SRV, error) {
+		return nil, errors.New("no such host")
+	}
+	if _, err := newSRVBalancer(lookup, KindWeightedPriority, time.Hour); err == nil {
+		t.Error("expected lookup error")
+	}
+}
+
+func TestSRVBalancer_CloseStopsRefresh(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	lookup := func(ctx context.Context) ([]*net.SRV, error) {
+		return []*net.SRV{{Target: "a.", Port: 1, Weight: 1}}, nil
+	}
+	b, err := newSRVBalancer(lookup, KindRoundRobin, time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var balancer ClosableBalancer = b
+	if err := balancer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := balancer.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	waitFor(t, func() bool { return runtime.NumGoroutine() <= before }, "refresh goroutine leaked after Close")
+
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty after Close", got)
+	}
+}
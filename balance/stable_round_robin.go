@@ -0,0 +1,75 @@
+package balance
+
+import (
+	"slices"
+	"sync"
+)
+
+// StableRoundRobinBalancer
+// 可以整体替换服务器列表的轮询：SetServers 时记住上一次返回的服务器，在新列表中从它之后继续，
+// 而不是按下标取模跳到任意位置，避免替换列表的瞬间同一个服务器被连续选中或某个服务器被跳过
+// 需要加锁，热路径上不如 RoundRobinBalancer 快，适合服务器列表会被整体刷新（如服务发现）的场景
+type StableRoundRobinBalancer struct {
+	servers []string
+	next    int    // 下一次返回的下标
+	last    string // 上一次返回的服务器
+	lock    sync.Mutex
+}
+
+// NewStableRoundRobinBalancer 复制一份 servers，从第一个服务器开始
+func NewStableRoundRobinBalancer(servers []string) *StableRoundRobinBalancer {
+	return &StableRoundRobinBalancer{
+		servers: slices.Clone(servers),
+	}
+}
+
+func (b *StableRoundRobinBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *StableRoundRobinBalancer) NextE() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	addr := b.servers[b.next]
+	b.next = (b.next + 1) % len(b.servers)
+	b.last = addr
+	return addr, nil
+}
+
+// SetServers 替换服务器列表，轮询从上一次返回的服务器之后继续
+// 上一次返回的服务器不在新列表中时，按旧列表的顺序往后找第一个仍然存在的服务器，从它开始；
+// 都不存在时从新列表的第一个开始
+func (b *StableRoundRobinBalancer) SetServers(servers []string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	old := b.servers
+	b.servers = slices.Clone(servers)
+	b.next = 0
+	if len(b.servers) == 0 || b.last == "" {
+		return
+	}
+	if i := slices.Index(b.servers, b.last); i >= 0 {
+		b.next = (i + 1) % len(b.servers)
+		return
+	}
+	start := slices.Index(old, b.last)
+	for k := 1; k < len(old); k++ {
+		if i := slices.Index(b.servers, old[(start+k)%len(old)]); i >= 0 {
+			b.next = i
+			return
+		}
+	}
+}
+
+// Servers 返回服务器列表的副本
+func (b *StableRoundRobinBalancer) Servers() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return slices.Clone(b.servers)
+}
@@ -0,0 +1,62 @@
+package balance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStableRoundRobinBalancer_SetServersMidRotation(t *testing.T) {
+	balancer := NewStableRoundRobinBalancer([]string{"a", "b", "c", "d"})
+	var got []string
+	next := func(n int) {
+		for i := 0; i < n; i++ {
+			got = append(got, balancer.Next())
+		}
+	}
+
+	// 扩容：从 b 之后继续，而不是按旧下标取模
+	next(2)
+	balancer.SetServers([]string{"e", "a", "b", "c", "d"})
+	next(5)
+	// 缩容且上一次返回的 b 被移除：c 也被移除，从旧顺序中 b 之后第一个仍然存在的 d 继续
+	balancer.SetServers([]string{"d", "e", "a"})
+	next(3)
+
+	want := []string{"a", "b", "c", "d", "e", "a", "b", "d", "e", "a"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("sequence = %v, want %v", got, want)
+	}
+	// 边界处没有服务器被连续选中
+	for i := 1; i < len(got); i++ {
+		if got[i] == got[i-1] {
+			t.Errorf("server %s served twice in a row at %d", got[i], i)
+		}
+	}
+}
+
+func TestStableRoundRobinBalancer_FullCycleAfterSwap(t *testing.T) {
+	balancer := NewStableRoundRobinBalancer([]string{"s1", "s2", "s3"})
+	balancer.Next()
+
+	// 替换后的一整轮中每个服务器恰好出现一次
+	servers := []string{"s3", "s4", "s1", "s2"}
+	balancer.SetServers(servers)
+	seen := make(map[string]int)
+	for i := 0; i < len(servers); i++ {
+		seen[balancer.Next()]++
+	}
+	for _, addr := range servers {
+		if seen[addr] != 1 {
+			t.Errorf("server %s served %d times in one cycle, want 1 (%v)", addr, seen[addr], seen)
+		}
+	}
+
+	balancer.SetServers(nil)
+	if _, err := balancer.NextE(); err != ErrEmptyPool {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+	balancer.SetServers([]string{"x"})
+	if got := balancer.Next(); got != "x" {
+		t.Errorf("Next() = %v, want x", got)
+	}
+}
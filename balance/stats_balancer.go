@@ -0,0 +1,44 @@
+package balance
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// StatsBalancer
+// 统计每个服务器被选中的次数，供监控面板展示实际的流量分布
+// 地址在第一次被返回时才注册，之后的计数只是原子加一，不需要加锁
+type StatsBalancer struct {
+	balancer Balancer
+	counts   sync.Map // addr -> *atomic.Int64
+}
+
+func NewStatsBalancer(b Balancer) *StatsBalancer {
+	return &StatsBalancer{
+		balancer: b,
+	}
+}
+
+// Next 透传给内部的负载均衡器，返回空字符串时不计数
+func (s *StatsBalancer) Next() string {
+	addr := s.balancer.Next()
+	if addr == "" {
+		return addr
+	}
+	c, ok := s.counts.Load(addr)
+	if !ok {
+		c, _ = s.counts.LoadOrStore(addr, new(atomic.Int64))
+	}
+	c.(*atomic.Int64).Add(1)
+	return addr
+}
+
+// Counts 返回各服务器被选中次数的快照，并发调用 Next 时各项计数可能不是同一时刻的值
+func (s *StatsBalancer) Counts() map[string]int64 {
+	counts := make(map[string]int64)
+	s.counts.Range(func(k, v any) bool {
+		counts[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return counts
+}
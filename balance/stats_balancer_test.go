@@ -0,0 +1,53 @@
+package balance
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestStatsBalancer_Counts(t *testing.T) {
+	balancer := NewStatsBalancer(newOrderedRoundRobin([]string{"s1", "s2", "s3"}))
+	if got := balancer.Counts(); len(got) != 0 {
+		t.Errorf("Counts() before Next = %v, want empty", got)
+	}
+
+	for i := 0; i < 7; i++ {
+		balancer.Next()
+	}
+	got := balancer.Counts()
+	if got["s1"] != 3 || got["s2"] != 2 || got["s3"] != 2 {
+		t.Errorf("Counts() = %v, want s1=3 s2=2 s3=2", got)
+	}
+
+	// 空地址不计数
+	empty := NewStatsBalancer(NewRoundRobinBalancer(nil))
+	empty.Next()
+	if got := empty.Counts(); len(got) != 0 {
+		t.Errorf("Counts() = %v, want empty", got)
+	}
+}
+
+func TestStatsBalancer_Concurrency(t *testing.T) {
+	balancer := NewStatsBalancer(NewRandomBalancer([]string{"s1", "s2", "s3", "s4"}))
+
+	const goroutines, calls = 50, 200
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				balancer.Next()
+			}
+		}()
+	}
+	wg.Wait()
+
+	var total int64
+	for _, n := range balancer.Counts() {
+		total += n
+	}
+	if total != goroutines*calls {
+		t.Errorf("sum of Counts() = %d, want %d", total, goroutines*calls)
+	}
+}
@@ -0,0 +1,39 @@
+package balance
+
+import (
+	"sync"
+)
+
+// StickyBalancer
+// 粘住一个服务器直到显式切换：正常流程用 Pick 拿到稳定的选择，失败重试时调用 Advance 轮询到下一个
+// 切换后的服务器会一直保持，直到下一次 Advance
+type StickyBalancer struct {
+	rr      *RoundRobinBalancer
+	current string
+	lock    sync.Mutex
+}
+
+// NewStickyBalancer 内部使用 RoundRobinBalancer，支持它的全部 Option
+func NewStickyBalancer(servers []string, opts ...Option) *StickyBalancer {
+	return &StickyBalancer{
+		rr: NewRoundRobinBalancer(servers, opts...).(*RoundRobinBalancer),
+	}
+}
+
+// Pick 返回当前粘住的服务器，第一次调用时选择一个；没有服务器时返回空字符串
+func (s *StickyBalancer) Pick() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.current == "" {
+		s.current = s.rr.Next()
+	}
+	return s.current
+}
+
+// Advance 切换到轮询顺序中的下一个服务器并返回它
+func (s *StickyBalancer) Advance() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.current = s.rr.Next()
+	return s.current
+}
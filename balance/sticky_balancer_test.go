@@ -0,0 +1,41 @@
+package balance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStickyBalancer_PickUntilAdvance(t *testing.T) {
+	servers := []string{"s1", "s2", "s3"}
+	balancer := NewStickyBalancer(servers)
+
+	first := balancer.Pick()
+	for i := 0; i < 10; i++ {
+		if got := balancer.Pick(); got != first {
+			t.Fatalf("Pick() = %v, want sticky %v", got, first)
+		}
+	}
+
+	// Advance 按轮询顺序前进，之后 Pick 粘住新的服务器
+	prev := first
+	for i := 0; i < 4; i++ {
+		want := servers[(slices.Index(servers, prev)+1)%len(servers)]
+		if got := balancer.Advance(); got != want {
+			t.Errorf("Advance() = %v, want %v", got, want)
+		}
+		if got := balancer.Pick(); got != want {
+			t.Errorf("Pick() after Advance = %v, want %v", got, want)
+		}
+		prev = want
+	}
+}
+
+func TestStickyBalancer_Empty(t *testing.T) {
+	balancer := NewStickyBalancer(nil)
+	if got := balancer.Pick(); got != "" {
+		t.Errorf("Pick() = %v, want empty string", got)
+	}
+	if got := balancer.Advance(); got != "" {
+		t.Errorf("Advance() = %v, want empty string", got)
+	}
+}
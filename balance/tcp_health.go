@@ -0,0 +1,142 @@
+package balance
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TCPHealthCheckedBalancer
+// 主动 TCP 健康检查：每隔 interval 探测一次服务器，不可达的服务器退出轮转，恢复后重新加入
+// 探测成功的连接会被缓存，下次直接用它做保活探测，而不是每次重新建连
+// 使用完需要调用 Close 停止后台 goroutine
+type TCPHealthCheckedBalancer struct {
+	*HealthBalancer
+	interval time.Duration
+	timeout  time.Duration
+	conns    map[string]net.Conn
+
+	stop      chan struct{}
+	done      chan struct{}
+	closed    atomic.Bool
+	closeOnce sync.Once
+}
+
+// NewTCPHealthCheckedBalancer 通过 ServerLister 从 b 拿到要探测的服务器，b 没有实现 ServerLister 时返回错误
+// 服务器为空时返回 ErrEmptyPool，interval 和 timeout 必须为正数，出错时不会启动后台 goroutine
+func NewTCPHealthCheckedBalancer(b Balancer, interval, timeout time.Duration) (*TCPHealthCheckedBalancer, error) {
+	l, ok := b.(ServerLister)
+	if !ok {
+		return nil, fmt.Errorf("balancer %T does not implement ServerLister", b)
+	}
+	servers := l.Servers()
+	if len(servers) == 0 {
+		return nil, ErrEmptyPool
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("probe interval must be positive, got: %v", interval)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("probe timeout must be positive, got: %v", timeout)
+	}
+	t := &TCPHealthCheckedBalancer{
+		HealthBalancer: NewHealthBalancer(b, servers),
+		interval:       interval,
+		timeout:        timeout,
+		conns:          make(map[string]net.Conn, len(servers)),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	go t.run()
+	return t, nil
+}
+
+func (t *TCPHealthCheckedBalancer) Next() string {
+	addr, _ := t.NextE()
+	return addr
+}
+
+func (t *TCPHealthCheckedBalancer) NextE() (string, error) {
+	if t.closed.Load() {
+		return "", ErrClosed
+	}
+	return t.HealthBalancer.NextE()
+}
+
+// Close 停止探测并关闭缓存的连接，可以重复调用
+func (t *TCPHealthCheckedBalancer) Close() error {
+	t.closeOnce.Do(func() {
+		t.closed.Store(true)
+		close(t.stop)
+		<-t.done
+	})
+	return nil
+}
+
+func (t *TCPHealthCheckedBalancer) run() {
+	defer close(t.done)
+	defer func() {
+		for _, conn := range t.conns {
+			conn.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		t.probeAll()
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeAll 并发探测所有服务器，一轮最多耗时一个 timeout，不会因为服务器多而推迟下一轮
+// 每个 goroutine 只处理自己的服务器，结果在全部完成后统一写回，conns 不需要加锁
+func (t *TCPHealthCheckedBalancer) probeAll() {
+	conns := make([]net.Conn, len(t.servers))
+	var wg sync.WaitGroup
+	for i, addr := range t.servers {
+		wg.Add(1)
+		go func(i int, addr string, cached net.Conn) {
+			defer wg.Done()
+			conns[i] = t.probe(addr, cached)
+		}(i, addr, t.conns[addr])
+	}
+	wg.Wait()
+
+	for i, addr := range t.servers {
+		if conns[i] == nil {
+			delete(t.conns, addr)
+		} else {
+			t.conns[addr] = conns[i]
+		}
+		t.SetHealthy(addr, conns[i] != nil)
+	}
+}
+
+// probe 优先复用缓存的连接：读超时说明连接仍然存活，读到 EOF 或其它错误说明对端已断开
+// 返回可用的连接，服务器不可达时返回 nil
+func (t *TCPHealthCheckedBalancer) probe(addr string, cached net.Conn) net.Conn {
+	if cached != nil {
+		cached.SetReadDeadline(time.Now().Add(time.Millisecond))
+		var buf [1]byte
+		_, err := cached.Read(buf[:])
+		var netErr net.Error
+		if err == nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return cached
+		}
+		cached.Close()
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, t.timeout)
+	if err != nil {
+		return nil
+	}
+	return conn
+}
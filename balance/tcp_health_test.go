@@ -0,0 +1,137 @@
+package balance
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testTCPServer 接受连接并保持，关闭时同时关闭监听和已建立的连接
+type testTCPServer struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func startTestTCPServer(t *testing.T, addr string) *testTCPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listen %s: %v", addr, err)
+	}
+	s := &testTCPServer{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+		}
+	}()
+	return s
+}
+
+func (s *testTCPServer) Close() {
+	s.ln.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal(msg)
+}
+
+func TestTCPHealthCheckedBalancer_LeavesAndRejoins(t *testing.T) {
+	s1 := startTestTCPServer(t, "127.0.0.1:0")
+	defer s1.Close()
+	s2 := startTestTCPServer(t, "127.0.0.1:0")
+	addr1, addr2 := s1.ln.Addr().String(), s2.ln.Addr().String()
+
+	balancer, err := NewTCPHealthCheckedBalancer(NewRoundRobinBalancer([]string{addr1, addr2}), 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewTCPHealthCheckedBalancer() error = %v", err)
+	}
+	defer balancer.Close()
+
+	waitFor(t, func() bool { return balancer.Available() == 2 }, "both servers should be healthy")
+
+	s2.Close()
+	waitFor(t, func() bool { return !balancer.IsAvailable(addr2) }, "closed server should leave rotation")
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(); got != addr1 {
+			t.Fatalf("Next() = %v, want %v", got, addr1)
+		}
+	}
+
+	s2 = startTestTCPServer(t, addr2)
+	defer s2.Close()
+	waitFor(t, func() bool { return balancer.IsAvailable(addr2) }, "reopened server should rejoin rotation")
+}
+
+func TestTCPHealthCheckedBalancer_Close(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	tcp, err := NewTCPHealthCheckedBalancer(NewRoundRobinBalancer([]string{"127.0.0.1:1"}), time.Millisecond, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewTCPHealthCheckedBalancer() error = %v", err)
+	}
+	var balancer ClosableBalancer = tcp
+	if err := balancer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	// 重复关闭是安全的
+	if err := balancer.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	waitFor(t, func() bool { return runtime.NumGoroutine() <= before }, "probe goroutine leaked after Close")
+
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty after Close", got)
+	}
+	if _, err := balancer.(ErrorBalancer).NextE(); !errors.Is(err, ErrClosed) {
+		t.Errorf("NextE() error = %v, want ErrClosed", err)
+	}
+}
+
+func TestTCPHealthCheckedBalancer_InvalidConfig(t *testing.T) {
+	before := runtime.NumGoroutine()
+	inner := NewRoundRobinBalancer([]string{"127.0.0.1:1"})
+
+	if _, err := NewTCPHealthCheckedBalancer(NewRoundRobinBalancer(nil), time.Millisecond, time.Millisecond); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("empty servers: error = %v, want ErrEmptyPool", err)
+	}
+	// 没有实现 ServerLister 时拿不到要探测的服务器
+	if _, err := NewTCPHealthCheckedBalancer(balancerFunc(inner.Next), time.Millisecond, time.Millisecond); err == nil {
+		t.Error("non-lister balancer: expected error")
+	}
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, err := NewTCPHealthCheckedBalancer(inner, d, time.Millisecond); err == nil {
+			t.Errorf("interval %v: expected error", d)
+		}
+		if _, err := NewTCPHealthCheckedBalancer(inner, time.Millisecond, d); err == nil {
+			t.Errorf("timeout %v: expected error", d)
+		}
+	}
+	// 配置错误时不启动探测 goroutine
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines = %d, want <= %d", after, before)
+	}
+}
@@ -0,0 +1,83 @@
+package balance
+
+import (
+	"time"
+)
+
+// KeyedBalancer 按 key 选择服务器的负载均衡器（一致性哈希、会话粘性等）
+type KeyedBalancer interface {
+	NextForKey(key string) string
+}
+
+// FallbackBalancer 能说明本次选择是否走了兜底（比如备用层级）的负载均衡器
+type FallbackBalancer interface {
+	NextWithFallback() (addr string, fallback bool)
+}
+
+// Decision 一次路由决策
+type Decision struct {
+	Time     time.Time
+	Key      string
+	Addr     string
+	Fallback bool
+}
+
+// TraceBufferBalancer
+// 在内存中保留最近 size 次路由决策，事故复盘时可以导出
+// 写入不加锁，不拖慢热路径，见 recentRing；只需要地址时使用更轻量的 HistoryBalancer
+type TraceBufferBalancer struct {
+	balancer Balancer
+	ring     *recentRing[Decision]
+	clock    Clock
+}
+
+func NewTraceBufferBalancer(b Balancer, size int, opts ...Option) *TraceBufferBalancer {
+	o := newOptions(opts)
+	return &TraceBufferBalancer{
+		balancer: b,
+		ring:     newRecentRing[Decision](size),
+		clock:    o.clock,
+	}
+}
+
+func (t *TraceBufferBalancer) Next() string {
+	var (
+		addr     string
+		fallback bool
+	)
+	if fb, ok := t.balancer.(FallbackBalancer); ok {
+		addr, fallback = fb.NextWithFallback()
+	} else {
+		addr = t.balancer.Next()
+	}
+	t.record("", addr, fallback)
+	return addr
+}
+
+// NextForKey 内部负载均衡器需要实现 KeyedBalancer，否则忽略 key 直接调用 Next
+func (t *TraceBufferBalancer) NextForKey(key string) string {
+	kb, ok := t.balancer.(KeyedBalancer)
+	if !ok {
+		addr := t.balancer.Next()
+		t.record(key, addr, false)
+		return addr
+	}
+	addr := kb.NextForKey(key)
+	t.record(key, addr, false)
+	return addr
+}
+
+// RecentDecisions 按时间顺序返回最近的决策，最多 size 条
+// 与写入并发时，可能缺少正在写入的那一条
+func (t *TraceBufferBalancer) RecentDecisions() []Decision {
+	return t.ring.snapshot()
+}
+
+func (t *TraceBufferBalancer) record(key, addr string, fallback bool) {
+	t.ring.add(Decision{
+		Time:     t.clock.Now(),
+		Key:      key,
+		Addr:     addr,
+		Fallback: fallback,
+	})
+}
@@ -0,0 +1,66 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTraceBufferBalancer_RetainsLastN(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	servers := make([]string, 10)
+	for i := range servers {
+		servers[i] = fmt.Sprintf("s%d", i)
+	}
+	balancer := NewTraceBufferBalancer(newOrderedRoundRobin(servers), 4, WithClock(clock))
+
+	if got := balancer.RecentDecisions(); len(got) != 0 {
+		t.Errorf("RecentDecisions() = %v, want empty", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		balancer.Next()
+		clock.Advance(time.Second)
+	}
+
+	got := balancer.RecentDecisions()
+	want := []string{"s6", "s7", "s8", "s9"}
+	if len(got) != len(want) {
+		t.Fatalf("RecentDecisions() returned %d entries, want %d", len(got), len(want))
+	}
+	for i, d := range got {
+		if d.Addr != want[i] {
+			t.Errorf("decision %d = %v, want %v", i, d.Addr, want[i])
+		}
+		if i > 0 && !d.Time.After(got[i-1].Time) {
+			t.Errorf("decisions out of order at %d", i)
+		}
+	}
+}
+
+func TestTraceBufferBalancer_FallbackAndKey(t *testing.T) {
+	tiered, err := NewWeightedPriorityBalancer([]WeightedTier{
+		{Servers: []*Server{{Addr: "primary", Weight: 1}}},
+		{Servers: []*Server{{Addr: "backup", Weight: 1}}},
+	})
+	if err != nil {
+		t.Fatalf("NewWeightedPriorityBalancer() error = %v", err)
+	}
+	balancer := NewTraceBufferBalancer(tiered, 8)
+
+	balancer.Next()
+	tiered.SetAvailable("primary", false)
+	balancer.Next()
+	balancer.NextForKey("user-1")
+
+	got := balancer.RecentDecisions()
+	if got[0].Addr != "primary" || got[0].Fallback {
+		t.Errorf("decision 0 = %+v, want primary without fallback", got[0])
+	}
+	if got[1].Addr != "backup" || !got[1].Fallback {
+		t.Errorf("decision 1 = %+v, want backup with fallback", got[1])
+	}
+	if got[2].Key != "user-1" {
+		t.Errorf("decision 2 key = %q, want user-1", got[2].Key)
+	}
+}
@@ -0,0 +1,124 @@
+package balance
+
+import (
+	"context"
+	"sync"
+)
+
+// queueWaiter 排队中的等待者，注意不能是零大小的类型，否则不同等待者的指针可能相等
+type queueWaiter struct {
+	ctx context.Context
+}
+
+// WaitQueueBalancer
+// 并发控制器：每个服务器最多 limit 个在途请求，都满了时 AcquireWait 按 FIFO 排队等待，
+// 任意服务器释放名额后唤醒队首的等待者。队列长度超过 maxQueue 时返回 ErrQueueFull
+type WaitQueueBalancer struct {
+	servers  []string
+	limit    int
+	maxQueue int
+	inflight map[string]int
+	queue    []*queueWaiter
+	lock     sync.Mutex
+	cond     *sync.Cond
+}
+
+func NewWaitQueueBalancer(servers []string, limit, maxQueue int) *WaitQueueBalancer {
+	b := &WaitQueueBalancer{
+		servers:  append([]string(nil), servers...),
+		limit:    limit,
+		maxQueue: maxQueue,
+		inflight: make(map[string]int, len(servers)),
+	}
+	b.cond = sync.NewCond(&b.lock)
+	return b
+}
+
+// AcquireWait 获取一个有空闲名额的服务器（在途请求最少的优先），没有时排队等待
+// ctx 取消时退出队列并返回 ctx.Err()；成功后需要调用 Release 归还名额
+func (b *WaitQueueBalancer) AcquireWait(ctx context.Context) (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	if len(b.queue) == 0 {
+		if addr := b.acquireLocked(); addr != "" {
+			return addr, nil
+		}
+	}
+	if len(b.queue) >= b.maxQueue {
+		return "", ErrQueueFull
+	}
+
+	w := &queueWaiter{ctx: ctx}
+	b.queue = append(b.queue, w)
+	// cond.Wait 无法感知 ctx，取消时主动唤醒
+	stop := context.AfterFunc(ctx, func() {
+		b.lock.Lock()
+		b.cond.Broadcast()
+		b.lock.Unlock()
+	})
+	defer stop()
+
+	for {
+		if b.queue[0] == w {
+			if addr := b.acquireLocked(); addr != "" {
+				b.queue = b.queue[1:]
+				// 可能还有空闲名额，让新的队首检查一下
+				b.cond.Broadcast()
+				return addr, nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			b.removeLocked(w)
+			b.cond.Broadcast()
+			return "", err
+		}
+		b.cond.Wait()
+	}
+}
+
+// Release 归还 addr 的一个名额，并唤醒等待者
+func (b *WaitQueueBalancer) Release(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.inflight[addr] > 0 {
+		b.inflight[addr]--
+		b.cond.Broadcast()
+	}
+}
+
+// QueueLen 当前排队的等待者数量
+func (b *WaitQueueBalancer) QueueLen() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.queue)
+}
+
+func (b *WaitQueueBalancer) acquireLocked() string {
+	best := ""
+	for _, addr := range b.servers {
+		n := b.inflight[addr]
+		if n >= b.limit {
+			continue
+		}
+		if best == "" || n < b.inflight[best] {
+			best = addr
+		}
+	}
+	if best != "" {
+		b.inflight[best]++
+	}
+	return best
+}
+
+func (b *WaitQueueBalancer) removeLocked(w *queueWaiter) {
+	for i, q := range b.queue {
+		if q == w {
+			b.queue = append(b.queue[:i], b.queue[i+1:]...)
+			return
+		}
+	}
+}
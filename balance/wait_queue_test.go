@@ -0,0 +1,95 @@
+package balance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitQueueBalancer_FIFO(t *testing.T) {
+	balancer := NewWaitQueueBalancer([]string{"s1"}, 1, 10)
+	ctx := context.Background()
+
+	addr, err := balancer.AcquireWait(ctx)
+	if err != nil || addr != "s1" {
+		t.Fatalf("AcquireWait() = %v, %v, want s1", addr, err)
+	}
+
+	// 依次入队，保证入队顺序确定
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			if _, err := balancer.AcquireWait(ctx); err != nil {
+				t.Errorf("waiter %d: %v", i, err)
+			}
+			order <- i
+		}(i)
+		waitFor(t, func() bool { return balancer.QueueLen() == i+1 }, "waiter did not enqueue")
+	}
+
+	// 每释放一个名额，按入队顺序唤醒下一个等待者
+	for want := 0; want < 3; want++ {
+		balancer.Release("s1")
+		select {
+		case got := <-order:
+			if got != want {
+				t.Errorf("woke waiter %d, want %d", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("waiter %d was not woken", want)
+		}
+	}
+}
+
+func TestWaitQueueBalancer_FreedSlotWakesWaiter(t *testing.T) {
+	balancer := NewWaitQueueBalancer([]string{"s1"}, 1, 1)
+	addr, _ := balancer.AcquireWait(context.Background())
+
+	got := make(chan string, 1)
+	go func() {
+		a, _ := balancer.AcquireWait(context.Background())
+		got <- a
+	}()
+	waitFor(t, func() bool { return balancer.QueueLen() == 1 }, "waiter did not enqueue")
+
+	balancer.Release(addr)
+	select {
+	case a := <-got:
+		if a != "s1" {
+			t.Errorf("AcquireWait() = %v, want s1", a)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not woken by release")
+	}
+}
+
+func TestWaitQueueBalancer_QueueFullAndCancel(t *testing.T) {
+	balancer := NewWaitQueueBalancer([]string{"s1"}, 1, 1)
+	balancer.AcquireWait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := balancer.AcquireWait(ctx)
+		errc <- err
+	}()
+	waitFor(t, func() bool { return balancer.QueueLen() == 1 }, "waiter did not enqueue")
+
+	if _, err := balancer.AcquireWait(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("AcquireWait() error = %v, want ErrQueueFull", err)
+	}
+
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("AcquireWait() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled waiter did not return")
+	}
+	if balancer.QueueLen() != 0 {
+		t.Errorf("QueueLen() = %d, want 0 after cancel", balancer.QueueLen())
+	}
+}
@@ -0,0 +1,127 @@
+package balance
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestRandomWeightBalancerWithLimits_LargeTotals(t *testing.T) {
+	// 总权重 40 亿，超过 int32 范围
+	servers := []*Server{
+		{Addr: "server1", Weight: 3_000_000_000},
+		{Addr: "server2", Weight: 1_000_000_000},
+	}
+	balancer, err := NewRandomWeightBalancerWithLimits(servers, 5_000_000_000, 10_000_000_000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results := make(map[string]int)
+	iterations := 10000
+	for i := 0; i < iterations; i++ {
+		results[balancer.Next()]++
+	}
+
+	share := float64(results["server1"]) / float64(iterations)
+	t.Logf("Distribution: %v", results)
+	if share < 0.73 || share > 0.77 {
+		t.Errorf("Expected server1 share around 0.75, got %.3f", share)
+	}
+}
+
+func TestRandomWeightBalancerWithLimits_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []*Server
+	}{
+		{"negative", []*Server{{Addr: "a", Weight: -1}}},
+		{"weight exceeds max", []*Server{{Addr: "a", Weight: 101}}},
+		{"total exceeds max", []*Server{{Addr: "a", Weight: 100}, {Addr: "b", Weight: 100}, {Addr: "c", Weight: 100}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewRandomWeightBalancerWithLimits(tt.servers, 100, 250); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestRandomWeightBalancerWithLimits_Overflow(t *testing.T) {
+	servers := []*Server{
+		{Addr: "a", Weight: math.MaxInt64},
+		{Addr: "b", Weight: math.MaxInt64},
+	}
+	if _, err := NewRandomWeightBalancerWithLimits(servers, math.MaxInt64, math.MaxInt64); err == nil {
+		t.Errorf("expected overflow error")
+	}
+}
+
+func TestSmoothRRWithLimits_LargeTotals(t *testing.T) {
+	nodes := []*Node{
+		{server: "a", weight: 3_000_000_000},
+		{server: "b", weight: 1_000_000_000},
+	}
+	balancer := NewSmoothRRBalancerWithLimits(nodes, 5_000_000_000, 10_000_000_000)
+
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		counts[balancer.Next(context.Background()).server]++
+	}
+	if counts["a"] != 300 || counts["b"] != 100 {
+		t.Errorf("expected a=300 b=100, got %v", counts)
+	}
+}
+
+func TestSmoothRRWithLimits_Overflow(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic for total weight overflow")
+		}
+	}()
+
+	nodes := []*Node{
+		{server: "a", weight: math.MaxInt64},
+		{server: "b", weight: math.MaxInt64},
+	}
+	_ = NewSmoothRRBalancerWithLimits(nodes, math.MaxInt64, math.MaxInt64)
+}
+
+func TestNewRandomWeightBalancerE(t *testing.T) {
+	tests := []struct {
+		name    string
+		servers []*Server
+		wantErr string
+	}{
+		{"near MaxInt", []*Server{{Addr: "a", Weight: math.MaxInt}, {Addr: "b", Weight: math.MaxInt}}, "server a weight 9223372036854775807 exceeds max 1000000"},
+		{"negative", []*Server{{Addr: "a", Weight: -1}}, "server a weight must not be negative, got: -1"},
+		{"total exceeds max", []*Server{{Addr: "a", Weight: maxWeight}, {Addr: "b", Weight: maxWeight}, {Addr: "c", Weight: maxWeight}, {Addr: "d", Weight: maxWeight},
+			{Addr: "e", Weight: maxWeight}, {Addr: "f", Weight: maxWeight}, {Addr: "g", Weight: maxWeight}, {Addr: "h", Weight: maxWeight},
+			{Addr: "i", Weight: maxWeight}, {Addr: "j", Weight: maxWeight}, {Addr: "k", Weight: 1}}, "total weight 10000001 exceeds max 10000000"},
+	}
+	for _, tt := range tests {
+		b, err := NewRandomWeightBalancerE(tt.servers)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+		if b != nil {
+			t.Errorf("%s: balancer = %v, want nil", tt.name, b)
+		}
+	}
+
+	b, err := NewRandomWeightBalancerE([]*Server{{Addr: "a", Weight: 1}})
+	if err != nil || b.Next() != "a" {
+		t.Errorf("valid servers: err = %v", err)
+	}
+}
+
+func TestRandomWeightBalancer_OverflowAtSelection(t *testing.T) {
+	// 未经校验构造的负载均衡器，选择时返回错误而不是 panic 或错误的分布
+	balancer := NewRandomWeightBalancer([]*Server{
+		{Addr: "a", Weight: math.MaxInt},
+		{Addr: "b", Weight: math.MaxInt},
+	})
+	if _, err := balancer.(ErrorBalancer).NextE(); err != ErrWeightOverflow {
+		t.Errorf("NextE() error = %v, want ErrWeightOverflow", err)
+	}
+}
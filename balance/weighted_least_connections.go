@@ -0,0 +1,91 @@
+package balance
+
+import (
+	"sync"
+)
+
+// WeightedLeastConnectionsBalancer
+// 加权最少连接：选择 在途请求数/权重 最小的服务器，权重为2倍的服务器可以承载2倍的连接
+// 比值相同时选权重大的，再相同时选下标最小的；权重不为正的服务器不参与选择
+// Next 选中后在途请求数加一，请求结束后需要调用 Done
+type WeightedLeastConnectionsBalancer struct {
+	servers []string
+	weights []int64
+	active  []int64
+	lock    sync.Mutex
+}
+
+func NewWeightedLeastConnectionsBalancer(servers []*Server) *WeightedLeastConnectionsBalancer {
+	b := &WeightedLeastConnectionsBalancer{
+		servers: make([]string, len(servers)),
+		weights: make([]int64, len(servers)),
+		active:  make([]int64, len(servers)),
+	}
+	for i, s := range servers {
+		b.servers[i] = s.Addr
+		b.weights[i] = int64(s.Weight)
+	}
+	return b
+}
+
+func (b *WeightedLeastConnectionsBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+// NextE 没有服务器时返回 ErrEmptyPool，没有权重为正的服务器时返回 ErrAllUnavailable
+func (b *WeightedLeastConnectionsBalancer) NextE() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return "", ErrEmptyPool
+	}
+	best := -1
+	for i, w := range b.weights {
+		if w <= 0 {
+			continue
+		}
+		if best < 0 || b.less(i, best) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "", ErrAllUnavailable
+	}
+	b.active[best]++
+	return b.servers[best], nil
+}
+
+// less 交叉相乘比较 active/weight，避免浮点误差
+func (b *WeightedLeastConnectionsBalancer) less(i, j int) bool {
+	li, lj := b.active[i]*b.weights[j], b.active[j]*b.weights[i]
+	if li != lj {
+		return li < lj
+	}
+	return b.weights[i] > b.weights[j]
+}
+
+// Done 请求结束，addr 的在途请求数减一，不会减到负数；未知地址会被忽略
+func (b *WeightedLeastConnectionsBalancer) Done(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, s := range b.servers {
+		if s == addr && b.active[i] > 0 {
+			b.active[i]--
+			return
+		}
+	}
+}
+
+// Active 返回 addr 当前的在途请求数
+func (b *WeightedLeastConnectionsBalancer) Active(addr string) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, s := range b.servers {
+		if s == addr {
+			return int(b.active[i])
+		}
+	}
+	return 0
+}
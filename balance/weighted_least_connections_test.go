@@ -0,0 +1,56 @@
+package balance
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestWeightedLeastConnectionsBalancer_SteadyLoad(t *testing.T) {
+	balancer := NewWeightedLeastConnectionsBalancer([]*Server{
+		{Addr: "light", Weight: 1},
+		{Addr: "heavy", Weight: 3},
+	})
+
+	// 保持 100 个在途请求，每次随机结束一个再发起一个
+	rng := rand.New(rand.NewSource(1))
+	inflight := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		inflight = append(inflight, balancer.Next())
+	}
+	for i := 0; i < 5000; i++ {
+		j := rng.Intn(len(inflight))
+		balancer.Done(inflight[j])
+		inflight[j] = balancer.Next()
+	}
+
+	light, heavy := balancer.Active("light"), balancer.Active("heavy")
+	if light+heavy != 100 {
+		t.Fatalf("active = %d + %d, want 100 in flight", light, heavy)
+	}
+	if ratio := float64(heavy) / float64(light); ratio < 2.7 || ratio > 3.3 {
+		t.Errorf("heavy/light connections = %d/%d (%.2f), want around 3", heavy, light, ratio)
+	}
+}
+
+func TestWeightedLeastConnectionsBalancer_TieBreak(t *testing.T) {
+	balancer := NewWeightedLeastConnectionsBalancer([]*Server{
+		{Addr: "a", Weight: 1},
+		{Addr: "b", Weight: 2},
+		{Addr: "off", Weight: 0},
+	})
+
+	// 比值相同时权重大的优先，权重为0的服务器不参与
+	want := []string{"b", "a", "b", "b", "a", "b"}
+	for _, w := range want {
+		if got := balancer.Next(); got != w {
+			t.Errorf("Next() = %v, want %v", got, w)
+		}
+	}
+
+	if _, err := NewWeightedLeastConnectionsBalancer(nil).NextE(); err != ErrEmptyPool {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+	if _, err := NewWeightedLeastConnectionsBalancer([]*Server{{Addr: "off", Weight: 0}}).NextE(); err != ErrAllUnavailable {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
@@ -0,0 +1,94 @@
+package balance
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// WeightedTier 一个优先级层级，层内按权重随机
+type WeightedTier struct {
+	Servers []*Server
+}
+
+// WeightedPriorityBalancer
+// 层级之间严格按优先级：只有前面所有层级都不可用时，才会使用下一层
+// 层级内部按权重随机，适合主/备机房路由，并在机房内按容量分配
+type WeightedPriorityBalancer struct {
+	tiers    [][]*Server
+	down     map[string]bool
+	rng      *rand.Rand
+	fallback rngFallback
+	lock     sync.Mutex
+}
+
+// NewWeightedPriorityBalancer 每个层级的权重按与 NewRandomWeightBalancerE 相同的规则校验（maxWeight、maxTotalWeight）
+func NewWeightedPriorityBalancer(tiers []WeightedTier) (*WeightedPriorityBalancer, error) {
+	for i, tier := range tiers {
+		if err := validateWeights(tier.Servers, maxWeight, maxTotalWeight); err != nil {
+			return nil, fmt.Errorf("tier %d: %w", i, err)
+		}
+	}
+	b := &WeightedPriorityBalancer{
+		tiers: make([][]*Server, 0, len(tiers)),
+		down:  make(map[string]bool),
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, tier := range tiers {
+		b.tiers = append(b.tiers, append([]*Server(nil), tier.Servers...))
+	}
+	return b, nil
+}
+
+// SetAvailable 标记服务器是否可用，不可用的服务器不参与选择
+func (b *WeightedPriorityBalancer) SetAvailable(addr string, available bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if available {
+		delete(b.down, addr)
+	} else {
+		b.down[addr] = true
+	}
+}
+
+func (b *WeightedPriorityBalancer) Next() string {
+	addr, _ := b.NextE()
+	return addr
+}
+
+func (b *WeightedPriorityBalancer) NextE() (string, error) {
+	addr, _, err := b.next()
+	return addr, err
+}
+
+// NextWithFallback fallback 为 true 表示不是从第一个层级选出的
+func (b *WeightedPriorityBalancer) NextWithFallback() (string, bool) {
+	addr, tier, _ := b.next()
+	return addr, tier > 0
+}
+
+// next 返回选中的服务器及其所在层级
+func (b *WeightedPriorityBalancer) next() (string, int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	empty := true
+	for i, tier := range b.tiers {
+		if len(tier) == 0 {
+			continue
+		}
+		empty = false
+		if addr := weightedPick(b.rng, &b.fallback, tier, b.available); addr != "" {
+			return addr, i, nil
+		}
+	}
+	if empty {
+		return "", -1, ErrEmptyPool
+	}
+	return "", -1, ErrAllUnavailable
+}
+
+func (b *WeightedPriorityBalancer) available(s *Server) bool {
+	return !b.down[s.Addr]
+}
@@ -0,0 +1,94 @@
+package balance
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestWeightedPriority(t *testing.T, tiers ...WeightedTier) *WeightedPriorityBalancer {
+	t.Helper()
+	if tiers == nil {
+		tiers = []WeightedTier{
+			{Servers: []*Server{{Addr: "dc1-a", Weight: 30}, {Addr: "dc1-b", Weight: 10}}},
+			{Servers: []*Server{{Addr: "dc2-a", Weight: 10}, {Addr: "dc2-b", Weight: 20}}},
+		}
+	}
+	b, err := NewWeightedPriorityBalancer(tiers)
+	if err != nil {
+		t.Fatalf("NewWeightedPriorityBalancer() error = %v", err)
+	}
+	return b
+}
+
+func TestWeightedPriorityBalancer_WeightedWithinTier(t *testing.T) {
+	balancer := newTestWeightedPriority(t)
+
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.Next()]++
+	}
+
+	if results["dc2-a"] > 0 || results["dc2-b"] > 0 {
+		t.Errorf("tier 1 should not receive traffic while tier 0 is available: %v", results)
+	}
+	ratio := float64(results["dc1-a"]) / float64(results["dc1-b"])
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected dc1-a/dc1-b ratio around 3.0, got %.2f", ratio)
+	}
+}
+
+func TestWeightedPriorityBalancer_FallsThrough(t *testing.T) {
+	balancer := newTestWeightedPriority(t)
+
+	// 只摘掉一台，流量仍留在 tier 0
+	balancer.SetAvailable("dc1-a", false)
+	for i := 0; i < 100; i++ {
+		if got := balancer.Next(); got != "dc1-b" {
+			t.Fatalf("Next() = %v, want dc1-b", got)
+		}
+	}
+
+	// tier 0 全部不可用，按权重流向 tier 1
+	balancer.SetAvailable("dc1-b", false)
+	results := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		results[balancer.Next()]++
+	}
+	ratio := float64(results["dc2-b"]) / float64(results["dc2-a"])
+	t.Logf("Distribution: %v", results)
+	if results["dc1-a"] > 0 || results["dc1-b"] > 0 {
+		t.Errorf("tier 0 servers should not be selected: %v", results)
+	}
+	if ratio < 1.5 || ratio > 2.5 {
+		t.Errorf("Expected dc2-b/dc2-a ratio around 2.0, got %.2f", ratio)
+	}
+
+	// 恢复后回到 tier 0
+	balancer.SetAvailable("dc1-a", true)
+	if got := balancer.Next(); got != "dc1-a" {
+		t.Errorf("Next() = %v, want dc1-a after recovery", got)
+	}
+}
+
+func TestWeightedPriorityBalancer_Errors(t *testing.T) {
+	if _, err := newTestWeightedPriority(t, []WeightedTier{}...).NextE(); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("NextE() error = %v, want ErrEmptyPool", err)
+	}
+
+	balancer := newTestWeightedPriority(t, WeightedTier{Servers: []*Server{{Addr: "a", Weight: 1}}})
+	balancer.SetAvailable("a", false)
+	if _, err := balancer.NextE(); !errors.Is(err, ErrAllUnavailable) {
+		t.Errorf("NextE() error = %v, want ErrAllUnavailable", err)
+	}
+}
+
+func TestWeightedPriorityBalancer_InvalidWeights(t *testing.T) {
+	_, err := NewWeightedPriorityBalancer([]WeightedTier{
+		{Servers: []*Server{{Addr: "a", Weight: 1}}},
+		{Servers: []*Server{{Addr: "b", Weight: maxWeight + 1}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "tier 1") {
+		t.Errorf("error = %v, want error naming tier 1", err)
+	}
+}
@@ -0,0 +1,45 @@
+package balance
+
+import (
+	"sync/atomic"
+)
+
+// WeightedRoundRobinBalancer
+// 经典的加权轮询：构造时把权重按最大公约数约分后展开成固定序列，按原子下标循环
+// 每个服务器在序列中连续出现，顺序完全可预测；需要交错分布时使用 NewSmoothRRBalancer
+type WeightedRoundRobinBalancer struct {
+	sequence []string
+	empty    bool
+	err      error // 构造时权重超限的错误
+	index    uint64
+}
+
+// NewWeightedRoundRobinBalancer 权重 10/20/30 会约分成长度为 6 的序列，权重小于等于0的服务器被忽略
+// 权重超出 maxWeight 或 maxTotalWeight 时不展开序列，NextE 返回对应的错误
+func NewWeightedRoundRobinBalancer(servers []*Server) Balancer {
+	sequence, err := expandWeights(servers)
+	return &WeightedRoundRobinBalancer{
+		sequence: sequence,
+		empty:    len(servers) == 0,
+		err:      err,
+	}
+}
+
+func (w *WeightedRoundRobinBalancer) Next() string {
+	addr, _ := w.NextE()
+	return addr
+}
+
+func (w *WeightedRoundRobinBalancer) NextE() (string, error) {
+	if w.empty {
+		return "", ErrEmptyPool
+	}
+	if w.err != nil {
+		return "", w.err
+	}
+	if len(w.sequence) == 0 {
+		return "", ErrAllUnavailable
+	}
+	n := atomic.AddUint64(&w.index, 1)
+	return w.sequence[(n-1)%uint64(len(w.sequence))], nil
+}
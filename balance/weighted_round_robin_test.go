@@ -0,0 +1,49 @@
+package balance
+
+import (
+	"testing"
+)
+
+func TestWeightedRoundRobinBalancer_ReducedCycle(t *testing.T) {
+	balancer := NewWeightedRoundRobinBalancer([]*Server{
+		{Addr: "a", Weight: 10},
+		{Addr: "b", Weight: 20},
+		{Addr: "c", Weight: 30},
+	})
+
+	// 10/20/30 约分为 1/2/3，一个周期长度为 6
+	want := []string{"a", "b", "b", "c", "c", "c"}
+	for cycle := 0; cycle < 3; cycle++ {
+		for i, w := range want {
+			if got := balancer.Next(); got != w {
+				t.Fatalf("cycle %d position %d: Next() = %v, want %v", cycle, i, got, w)
+			}
+		}
+	}
+	if n := len(balancer.(*WeightedRoundRobinBalancer).sequence); n != 6 {
+		t.Errorf("sequence length = %d, want 6", n)
+	}
+}
+
+func TestWeightedRoundRobinBalancer_Errors(t *testing.T) {
+	if _, err := NewWeightedRoundRobinBalancer(nil).(ErrorBalancer).NextE(); err != ErrEmptyPool {
+		t.Errorf("empty: error = %v, want ErrEmptyPool", err)
+	}
+	zero := NewWeightedRoundRobinBalancer([]*Server{{Addr: "a", Weight: 0}})
+	if _, err := zero.(ErrorBalancer).NextE(); err != ErrAllUnavailable {
+		t.Errorf("zero weights: error = %v, want ErrAllUnavailable", err)
+	}
+}
+
+func TestWeightedRoundRobinBalancer_WeightLimits(t *testing.T) {
+	balancer := NewWeightedRoundRobinBalancer([]*Server{
+		{Addr: "a", Weight: maxWeight + 1},
+		{Addr: "b", Weight: 1},
+	})
+	if _, err := balancer.(ErrorBalancer).NextE(); err == nil {
+		t.Error("expected error for weight above maxWeight")
+	}
+	if n := len(balancer.(*WeightedRoundRobinBalancer).sequence); n != 0 {
+		t.Errorf("sequence length = %d, want 0", n)
+	}
+}
@@ -0,0 +1,93 @@
+package balance
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+type preferredZoneKey struct{}
+
+// WithPreferredZone 在 ctx 中记录请求链偏好的可用区，通常是上一跳所在的可用区
+func WithPreferredZone(ctx context.Context, zone string) context.Context {
+	return context.WithValue(ctx, preferredZoneKey{}, zone)
+}
+
+// PreferredZone 读取 ctx 中偏好的可用区
+func PreferredZone(ctx context.Context) (string, bool) {
+	zone, ok := ctx.Value(preferredZoneKey{}).(string)
+	return zone, ok && zone != ""
+}
+
+// ZoneAffinityBalancer
+// 软性的可用区亲和：ctx 中有偏好可用区时，该可用区服务器的权重乘以 affinity，
+// 流量向它倾斜，但其它可用区仍然会分到一部分，这一点与严格的按区回退不同
+type ZoneAffinityBalancer struct {
+	zones    []string
+	servers  map[string][]*Server
+	affinity float64
+	rng      *rand.Rand
+	lock     sync.Mutex
+}
+
+// NewZoneAffinityBalancer affinity 小于 1 时按 1 处理（没有倾斜）
+func NewZoneAffinityBalancer(zones map[string][]*Server, affinity float64) *ZoneAffinityBalancer {
+	b := &ZoneAffinityBalancer{
+		servers:  make(map[string][]*Server, len(zones)),
+		affinity: max(affinity, 1),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for zone, servers := range zones {
+		b.zones = append(b.zones, zone)
+		b.servers[zone] = append([]*Server(nil), servers...)
+	}
+	sort.Strings(b.zones)
+	return b
+}
+
+func (b *ZoneAffinityBalancer) Next(ctx context.Context) string {
+	preferred, _ := PreferredZone(ctx)
+
+	var total float64
+	for _, zone := range b.zones {
+		for _, s := range b.servers[zone] {
+			total += b.weight(zone, preferred, s)
+		}
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	b.lock.Lock()
+	point := b.rng.Float64() * total
+	b.lock.Unlock()
+
+	var last string
+	for _, zone := range b.zones {
+		for _, s := range b.servers[zone] {
+			w := b.weight(zone, preferred, s)
+			if w <= 0 {
+				continue
+			}
+			last = s.Addr
+			point -= w
+			if point < 0 {
+				return s.Addr
+			}
+		}
+	}
+	// 浮点误差兜底
+	return last
+}
+
+func (b *ZoneAffinityBalancer) weight(zone, preferred string, s *Server) float64 {
+	if s.Weight <= 0 {
+		return 0
+	}
+	if zone == preferred {
+		return float64(s.Weight) * b.affinity
+	}
+	return float64(s.Weight)
+}
@@ -0,0 +1,53 @@
+package balance
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func zoneAShare(b *ZoneAffinityBalancer, ctx context.Context, iterations int) float64 {
+	hits := 0
+	for i := 0; i < iterations; i++ {
+		if strings.HasPrefix(b.Next(ctx), "a") {
+			hits++
+		}
+	}
+	return float64(hits) / float64(iterations)
+}
+
+func TestZoneAffinityBalancer_SoftPreference(t *testing.T) {
+	balancer := NewZoneAffinityBalancer(map[string][]*Server{
+		"zone-a": {{Addr: "a1", Weight: 1}, {Addr: "a2", Weight: 1}},
+		"zone-b": {{Addr: "b1", Weight: 1}, {Addr: "b2", Weight: 1}},
+	}, 3)
+
+	// 没有偏好时各占一半
+	if share := zoneAShare(balancer, context.Background(), 20000); share < 0.47 || share > 0.53 {
+		t.Errorf("no preference: zone-a share = %.3f, want ~0.5", share)
+	}
+
+	// 偏好 zone-a，权重 3:1，占比约 0.75，zone-b 仍有流量
+	ctx := WithPreferredZone(context.Background(), "zone-a")
+	share := zoneAShare(balancer, ctx, 20000)
+	if share < 0.72 || share > 0.78 {
+		t.Errorf("preferred zone-a share = %.3f, want ~0.75", share)
+	}
+	if share == 1 {
+		t.Error("other zones should still receive traffic")
+	}
+}
+
+func TestZoneAffinityBalancer_Context(t *testing.T) {
+	if _, ok := PreferredZone(context.Background()); ok {
+		t.Error("PreferredZone() should be false without zone")
+	}
+	zone, ok := PreferredZone(WithPreferredZone(context.Background(), "zone-a"))
+	if !ok || zone != "zone-a" {
+		t.Errorf("PreferredZone() = %v, %v, want zone-a, true", zone, ok)
+	}
+
+	if got := NewZoneAffinityBalancer(nil, 2).Next(context.Background()); got != "" {
+		t.Errorf("Next() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,53 @@
+package balance
+
+import (
+	"sort"
+)
+
+// ZoneAwareBalancer
+// 两级路由：优先使用本地可用区，本地可用区返回空（没有可用服务器）时才按可用区名称顺序溢出到其它可用区
+// 与 ZoneAffinityBalancer 的软性倾斜不同，这里是严格的本地优先
+type ZoneAwareBalancer struct {
+	preferred string
+	local     Balancer
+	others    []Balancer
+}
+
+// NewZoneAwareBalancer preferred 不在 zones 中时，所有可用区按名称顺序依次尝试
+func NewZoneAwareBalancer(zones map[string]Balancer, preferred string) *ZoneAwareBalancer {
+	b := &ZoneAwareBalancer{
+		preferred: preferred,
+		local:     zones[preferred],
+	}
+	names := make([]string, 0, len(zones))
+	for zone := range zones {
+		if zone != preferred {
+			names = append(names, zone)
+		}
+	}
+	sort.Strings(names)
+	for _, zone := range names {
+		b.others = append(b.others, zones[zone])
+	}
+	return b
+}
+
+func (b *ZoneAwareBalancer) Next() string {
+	addr, _ := b.NextWithFallback()
+	return addr
+}
+
+// NextWithFallback fallback 为 true 表示本地可用区没有可用服务器，选择溢出到了其它可用区
+func (b *ZoneAwareBalancer) NextWithFallback() (string, bool) {
+	if b.local != nil {
+		if addr := b.local.Next(); addr != "" {
+			return addr, false
+		}
+	}
+	for _, zone := range b.others {
+		if addr := zone.Next(); addr != "" {
+			return addr, true
+		}
+	}
+	return "", false
+}
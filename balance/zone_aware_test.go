@@ -0,0 +1,53 @@
+package balance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZoneAwareBalancer_PrefersLocal(t *testing.T) {
+	balancer := NewZoneAwareBalancer(map[string]Balancer{
+		"zone-a": NewRoundRobinBalancer([]string{"a1", "a2"}),
+		"zone-b": NewRoundRobinBalancer([]string{"b1", "b2"}),
+	}, "zone-a")
+
+	for i := 0; i < 100; i++ {
+		addr, fallback := balancer.NextWithFallback()
+		if !strings.HasPrefix(addr, "a") || fallback {
+			t.Fatalf("NextWithFallback() = %v, %v, want local zone-a server", addr, fallback)
+		}
+	}
+}
+
+func TestZoneAwareBalancer_SpillsOver(t *testing.T) {
+	local := NewRoundRobinBalancer([]string{"a1"}).(*RoundRobinBalancer)
+	balancer := NewZoneAwareBalancer(map[string]Balancer{
+		"zone-a": local,
+		"zone-c": NewRoundRobinBalancer([]string{"c1"}),
+		"zone-b": NewRoundRobinBalancer([]string{"b1"}),
+	}, "zone-a")
+
+	// 本地可用区清空后，按可用区名称顺序溢出到 zone-b
+	local.RemoveServer("a1")
+	for i := 0; i < 10; i++ {
+		addr, fallback := balancer.NextWithFallback()
+		if addr != "b1" || !fallback {
+			t.Fatalf("NextWithFallback() = %v, %v, want b1, true", addr, fallback)
+		}
+	}
+
+	// 本地恢复后立刻回到本地
+	local.AddServer("a1")
+	if got := balancer.Next(); got != "a1" {
+		t.Errorf("Next() = %v, want a1 after local zone recovers", got)
+	}
+}
+
+func TestZoneAwareBalancer_AllEmpty(t *testing.T) {
+	balancer := NewZoneAwareBalancer(map[string]Balancer{
+		"zone-a": NewRoundRobinBalancer(nil),
+	}, "zone-x")
+	if got := balancer.Next(); got != "" {
+		t.Errorf("Next() = %v, want empty", got)
+	}
+}